@@ -0,0 +1,79 @@
+package bloomfilter
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// blockBits は1ブロックのビット数（一般的なキャッシュライン64バイト分）
+const blockBits = 512
+
+// BlockedBloomFilter はキャッシュ局所性を高めたBloom Filter
+// 最初のハッシュでキャッシュライン大（512ビット）のブロックを1つ選び、
+// 残りのハッシュはそのブロック内のビットだけを指す
+// 1回のAdd/Testで触れるメモリが1つのキャッシュラインに収まるため、大きなフィルタで高速になる
+// 代わりにブロックごとの埋まり方にばらつきが出るため、同じサイズの偽陽性率はやや悪化する
+type BlockedBloomFilter struct {
+	filter    *BloomFilter // ビット配列と統計情報を保持するフィルタ
+	numBlocks int          // ブロック数
+}
+
+// NewBlockedBloomFilter は新しいBlockedBloomFilterを作成
+// パラメータの意味と最適サイズの計算はNewBloomFilterと同じ
+// 全体のサイズはブロックサイズの倍数に切り上げる
+func NewBlockedBloomFilter(expectedItems int, falsePositiveRate float64) *BlockedBloomFilter {
+	bf := NewBloomFilter(expectedItems, falsePositiveRate)
+
+	numBlocks := (bf.size + blockBits - 1) / blockBits
+	bf.size = numBlocks * blockBits
+	bf.bitArray = make([]uint64, numWords(bf.size))
+
+	return &BlockedBloomFilter{
+		filter:    bf,
+		numBlocks: numBlocks,
+	}
+}
+
+// getHashes はデータに対してブロック内のインデックスを計算
+// SHA-256のダイジェストの先頭8バイトでブロックを選び、
+// 続く16バイトをダブルハッシュ法の基底ハッシュとしてブロック内の位置を決める
+func (bbf *BlockedBloomFilter) getHashes(data []byte) []int {
+	digest := sha256.Sum256(data)
+	block := int(binary.LittleEndian.Uint64(digest[0:8]) % uint64(bbf.numBlocks))
+	h1 := binary.LittleEndian.Uint64(digest[8:16])
+	h2 := binary.LittleEndian.Uint64(digest[16:24])
+
+	hashes := doubleHashIndices(h1, h2, bbf.filter.numHashes, blockBits, 0)
+	for i := range hashes {
+		hashes[i] += block * blockBits
+	}
+	return hashes
+}
+
+// Add はアイテムを追加
+func (bbf *BlockedBloomFilter) Add(item string) {
+	for _, hash := range bbf.getHashes([]byte(item)) {
+		bbf.filter.setBit(hash)
+	}
+
+	bbf.filter.numItems++
+}
+
+// Test はアイテムが存在する可能性があるかテスト
+func (bbf *BlockedBloomFilter) Test(item string) bool {
+	for _, hash := range bbf.getHashes([]byte(item)) {
+		if !bbf.filter.getBit(hash) {
+			return false // 確実に存在しない
+		}
+	}
+
+	return true // 存在する可能性がある
+}
+
+// Stats は統計情報を返す（BloomFilter.Statsと同じ項目にnum_blocksを加えたもの）
+// false_positiveはブロック化を考慮しない理論値のため、実際の偽陽性率より低めになる
+func (bbf *BlockedBloomFilter) Stats() map[string]interface{} {
+	stats := bbf.filter.Stats()
+	stats["num_blocks"] = bbf.numBlocks
+	return stats
+}
@@ -0,0 +1,50 @@
+package bloomfilter
+
+import "testing"
+
+func TestBlockedFilterProbesStayInOneBlock(t *testing.T) {
+	keys := benchKeys(10_000)
+	bbf := NewBlockedBloomFilter(len(keys), 0.01)
+	for _, key := range keys {
+		bbf.Add(key)
+	}
+
+	for _, key := range keys {
+		if !bbf.Test(key) {
+			t.Fatalf("Test(%q) = false after Add", key)
+		}
+		hashes := bbf.getHashes([]byte(key))
+		block := hashes[0] / blockBits
+		for _, h := range hashes {
+			if h/blockBits != block {
+				t.Fatalf("%q probes blocks %d and %d, want a single block", key, block, h/blockBits)
+			}
+		}
+	}
+}
+
+// BenchmarkBlockedTest は数百万アイテムのフィルタでBlockedBloomFilterとBloomFilterのTestを比較する
+// フィルタがキャッシュに収まらない大きさだと、1つのキャッシュラインしか触れない分だけ速くなる
+func BenchmarkBlockedTest(b *testing.B) {
+	keys := benchKeys(4_000_000)
+
+	b.Run("plain", func(b *testing.B) {
+		bf := filledFilter(keys)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			bf.Test(keys[i%len(keys)])
+		}
+	})
+	b.Run("blocked", func(b *testing.B) {
+		bbf := NewBlockedBloomFilter(len(keys), 0.01)
+		for _, key := range keys {
+			bbf.Add(key)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			bbf.Test(keys[i%len(keys)])
+		}
+	})
+}
@@ -0,0 +1,828 @@
+// Package bloomfilter はBloom Filterとその派生（カウンティング、スケーラブル、ブロック化など）を提供する
+package bloomfilter
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+	"math/bits"
+)
+
+// hashScheme はダブルハッシュ法の基底ハッシュの種類
+type hashScheme uint8
+
+const (
+	schemeSHA256  hashScheme = iota // SHA-256（デフォルト）
+	schemeMurmur3                   // MurmurHash3 x64 128ビット
+)
+
+// BloomFilter はBloom Filterのデータ構造
+type BloomFilter struct {
+	bitArray  []uint64           // ビット配列（64ビットずつ詰めて保持）
+	size      int                // ビット配列のサイズ
+	hashFuncs []hash.Hash        // 呼び出し側が指定したハッシュ関数（nilならダブルハッシュ法）
+	newHashes []func() hash.Hash // hashFuncsを生成する関数（コピーに新しいインスタンスを渡すため保持）
+	numHashes int                // ハッシュ関数の数
+	numItems  int                // 追加されたアイテム数
+	setBits   int                // 立っているビットの数（ビット配列の走査を避けるため増分で管理）
+	seed      uint64             // ハッシュに混ぜるシード（0ならシードなし）
+	scheme    hashScheme         // 基底ハッシュの種類
+	capacity  int                // 作成時に想定したアイテム数
+}
+
+// NewBloomFilter は新しいBloom Filterを作成
+// expectedItems: 予想されるアイテム数
+// falsePositiveRate: 偽陽性率 (0.0 < rate < 1.0)
+// パラメータが不正な場合はpanicする。入力を検証したい場合はNewBloomFilterCheckedを使う
+func NewBloomFilter(expectedItems int, falsePositiveRate float64) *BloomFilter {
+	bf, err := NewBloomFilterChecked(expectedItems, falsePositiveRate)
+	if err != nil {
+		panic(err)
+	}
+	return bf
+}
+
+// NewBloomFilterChecked はパラメータを検証してBloom Filterを作成
+// expectedItemsが正でない場合や、falsePositiveRateが0より大きく1未満でない場合はエラーを返す
+func NewBloomFilterChecked(expectedItems int, falsePositiveRate float64) (*BloomFilter, error) {
+	if expectedItems <= 0 {
+		return nil, fmt.Errorf("expected items must be positive, got %d", expectedItems)
+	}
+	if !(falsePositiveRate > 0 && falsePositiveRate < 1) {
+		return nil, fmt.Errorf("false positive rate must be in (0, 1), got %v", falsePositiveRate)
+	}
+
+	size, numHashes := OptimalParameters(expectedItems, falsePositiveRate)
+
+	return &BloomFilter{
+		bitArray:  make([]uint64, numWords(size)),
+		size:      size,
+		numHashes: numHashes,
+		numItems:  0,
+		capacity:  expectedItems,
+	}, nil
+}
+
+// OptimalParameters は目標の偽陽性率を満たす最適なビット配列サイズとハッシュ関数の数を計算
+// メモリ使用量（size/8バイト）を確保前に見積もる容量計画に使える
+// 例: 1000アイテム、1%なら約9586ビット、7個のハッシュ関数
+func OptimalParameters(expectedItems int, falsePositiveRate float64) (size int, numHashes int) {
+	// 最適なビット配列サイズを計算: m = -n * ln(p) / (ln 2)^2
+	size = int(math.Ceil(float64(expectedItems) * math.Log(falsePositiveRate) / math.Log(1.0/math.Pow(2.0, math.Log(2.0)))))
+
+	// 最適なハッシュ関数の数を計算: k = (m/n) * ln 2
+	numHashes = int(math.Ceil(float64(size) / float64(expectedItems) * math.Log(2.0)))
+
+	// 最小値を保証
+	if size < 1 {
+		size = 1
+	}
+	if numHashes < 1 {
+		numHashes = 1
+	}
+
+	return size, numHashes
+}
+
+// NewBloomFilterWithSeed はシードを指定してBloom Filterを作成
+// 同じシードなら実行ごとに同じビット配置になり、異なるシードのフィルタ同士では
+// 同じアイテムが異なる位置に配置される（衝突の仕方が相関しない）
+// Testは同じシードで作成したフィルタに対してのみ意味を持ち、
+// 異なるシードのフィルタ同士はUnionなどで組み合わせられない
+// シード0はNewBloomFilterと同じ配置になる
+func NewBloomFilterWithSeed(expectedItems int, falsePositiveRate float64, seed uint64) *BloomFilter {
+	bf := NewBloomFilter(expectedItems, falsePositiveRate)
+	bf.seed = seed
+	return bf
+}
+
+// NewBloomFilterMurmur は基底ハッシュにMurmurHash3を使うBloom Filterを作成
+// 暗号学的ハッシュは所属判定には過剰なため、SHA-256より高速に動作する
+// メンバーシップの意味はNewBloomFilterと同じだが、ビット配置は異なるため
+// SHA-256のフィルタとはUnionなどで組み合わせられない
+func NewBloomFilterMurmur(expectedItems int, falsePositiveRate float64) *BloomFilter {
+	bf := NewBloomFilter(expectedItems, falsePositiveRate)
+	bf.scheme = schemeMurmur3
+	return bf
+}
+
+// NewBloomFilterWithHashes は呼び出し側が指定したハッシュ関数を使うBloom Filterを作成
+// size: ビット配列のサイズ, numHashes: ハッシュ関数の数（len(hashes)と一致する必要がある）
+// hashesは sha1.New のようなhash.Hashを生成する関数で、フィルタごとに新しいインスタンスを作る
+// i番目のインデックスはhashes[i]が生成するハッシュのダイジェストの先頭8バイトをsizeで割った余りになる
+// ハッシュ関数同士が十分に異なることは呼び出し側が保証すること
+// （同じ関数を複数渡すと同じビットを指すだけで偽陽性率が悪化する）
+// hash.Hashは状態を持つため、このフィルタは並行に使用できない（Cloneしたものは別々に使える）
+// リクエストの []hash.Hash ではなく生成関数を受け取るのは、インスタンスを共有すると
+// 元のフィルタとCloneしたフィルタが同じhash.Hashを同時に書き換えてしまい、goroutine安全でないため
+// また、不正な引数でpanicさせず呼び出し側で扱えるよう、*BloomFilterに加えてエラーも返す
+func NewBloomFilterWithHashes(size, numHashes int, hashes []func() hash.Hash) (*BloomFilter, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("size must be positive, got %d", size)
+	}
+	if numHashes < 1 {
+		return nil, fmt.Errorf("num hashes must be positive, got %d", numHashes)
+	}
+	if len(hashes) != numHashes {
+		return nil, fmt.Errorf("got %d hash functions, expected %d", len(hashes), numHashes)
+	}
+	hashFuncs := make([]hash.Hash, len(hashes))
+	for i, newHash := range hashes {
+		if newHash == nil {
+			return nil, fmt.Errorf("hash function %d is nil", i)
+		}
+		if hashFuncs[i] = newHash(); hashFuncs[i] == nil {
+			return nil, fmt.Errorf("hash function %d returned nil", i)
+		}
+	}
+
+	return &BloomFilter{
+		bitArray:  make([]uint64, numWords(size)),
+		size:      size,
+		hashFuncs: hashFuncs,
+		newHashes: hashes,
+		numHashes: numHashes,
+		numItems:  0,
+		capacity:  capacityFor(size, numHashes),
+	}, nil
+}
+
+// capacityFor はサイズとハッシュ関数の数が最適となるアイテム数 n = (m/k) * ln 2 を返す
+func capacityFor(size, numHashes int) int {
+	return max(int(float64(size)/float64(numHashes)*math.Ln2), 1)
+}
+
+// numWords はsizeビットを保持するのに必要なuint64の数を返す
+func numWords(size int) int {
+	return (size + 63) / 64
+}
+
+// setBit はi番目のビットを立てる（0から1に変わった場合はsetBitsを増やす）
+func (bf *BloomFilter) setBit(i int) {
+	if !bf.getBit(i) {
+		bf.bitArray[i/64] |= 1 << (i % 64)
+		bf.setBits++
+	}
+}
+
+// getBit はi番目のビットが立っているかを返す
+func (bf *BloomFilter) getBit(i int) bool {
+	return bf.bitArray[i/64]&(1<<(i%64)) != 0
+}
+
+// clearBit はi番目のビットを下ろす（1から0に変わった場合はsetBitsを減らす）
+func (bf *BloomFilter) clearBit(i int) {
+	if bf.getBit(i) {
+		bf.bitArray[i/64] &^= 1 << (i % 64)
+		bf.setBits--
+	}
+}
+
+// getHashes はデータに対してすべてのハッシュ値を計算
+func (bf *BloomFilter) getHashes(data []byte) []int {
+	if bf.hashFuncs != nil {
+		return customHashIndices(bf.hashFuncs, data, bf.size)
+	}
+	if bf.scheme == schemeMurmur3 {
+		h1, h2 := murmur3Sum128(data, 0)
+		return doubleHashIndices(h1, h2, bf.numHashes, bf.size, bf.seed)
+	}
+	return hashIndices(data, bf.numHashes, bf.size, bf.seed)
+}
+
+// customHashIndices は各ハッシュ関数でデータをハッシュし、サイズsizeの配列のインデックスに変換
+func customHashIndices(hashFuncs []hash.Hash, data []byte, size int) []int {
+	hashes := make([]int, len(hashFuncs))
+
+	for i, hashFunc := range hashFuncs {
+		hashFunc.Reset()
+		hashFunc.Write(data)
+
+		// ダイジェストの先頭8バイト（短い場合は全体）を64ビット値として使う
+		var hashValue uint64
+		for _, b := range hashFunc.Sum(nil)[:min(8, hashFunc.Size())] {
+			hashValue = hashValue<<8 | uint64(b)
+		}
+		hashes[i] = int(hashValue % uint64(size))
+	}
+
+	return hashes
+}
+
+// hashIndices はSHA-256を基底ハッシュとしてデータからnumHashes個のインデックスを計算
+// SHA-256のダイジェストを1回だけ計算し、その前半と後半を2つの基底ハッシュh1, h2とする
+func hashIndices(data []byte, numHashes, size int, seed uint64) []int {
+	digest := sha256.Sum256(data)
+	h1 := binary.LittleEndian.Uint64(digest[0:8])
+	h2 := binary.LittleEndian.Uint64(digest[8:16])
+	return doubleHashIndices(h1, h2, numHashes, size, seed)
+}
+
+// doubleHashIndices は2つの基底ハッシュh1, h2からnumHashes個のインデックスを計算
+// Kirsch-Mitzenmacherのダブルハッシュ法により、i番目のインデックスを (h1 + i*h2) % size で求める
+// ハッシュ関数をnumHashes回計算するのと比べて偽陽性率はほぼ変わらず、大幅に高速
+// h1, h2は64ビット値のまま使うため、sizeが32ビットを超える大きなフィルタでも
+// インデックスの上位ビットまで使われる
+// seedが0でなければh1, h2にシードから導いた値を混ぜる
+func doubleHashIndices(h1, h2 uint64, numHashes, size int, seed uint64) []int {
+	// fmix64(0) == 0 のため、シード0では配置が変わらない
+	h1 ^= fmix64(seed)
+	h2 ^= bits.RotateLeft64(fmix64(seed), 32)
+
+	hashes := make([]int, numHashes)
+	for i := range hashes {
+		hashes[i] = int((h1 + uint64(i)*h2) % uint64(size))
+	}
+
+	return hashes
+}
+
+// Add はBloom Filterにアイテムを追加
+func (bf *BloomFilter) Add(item string) {
+	bf.AddBytes([]byte(item))
+}
+
+// AddBytes はバイト列のアイテムをBloom Filterに追加
+// ネットワークから受け取った[]byteを文字列に変換せずそのまま使える
+func (bf *BloomFilter) AddBytes(data []byte) {
+	hashes := bf.getHashes(data)
+
+	for _, hash := range hashes {
+		bf.setBit(hash)
+	}
+
+	bf.numItems++
+}
+
+// maxLineLength はAddFromReaderで扱える1行の最大バイト数
+const maxLineLength = 1 << 20
+
+// AddFromReader は改行区切りのキーをrから1行ずつ読み込んで追加する
+// ファイル全体をメモリに読み込まずに大量のキーを投入できる
+// 追加した行数と、読み込み中のエラー（maxLineLengthを超える行を含む）を返す
+func (bf *BloomFilter) AddFromReader(r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+
+	count := 0
+	for scanner.Scan() {
+		bf.AddBytes(scanner.Bytes())
+		count++
+	}
+
+	return count, scanner.Err()
+}
+
+// Test はアイテムがBloom Filterに存在する可能性があるかテスト
+// true: 存在する可能性がある（偽陽性の可能性あり）
+// false: 確実に存在しない
+func (bf *BloomFilter) Test(item string) bool {
+	return bf.TestBytes([]byte(item))
+}
+
+// TestBytes はバイト列のアイテムが存在する可能性があるかテスト
+func (bf *BloomFilter) TestBytes(data []byte) bool {
+	hashes := bf.getHashes(data)
+
+	for _, hash := range hashes {
+		if !bf.getBit(hash) {
+			return false // 確実に存在しない
+		}
+	}
+
+	return true // 存在する可能性がある
+}
+
+// TestAll は複数のアイテムをまとめてテストし、入力と同じ順序で結果を返す
+// 結果の各要素はTestを個別に呼んだ場合と同じになる
+func (bf *BloomFilter) TestAll(items []string) []bool {
+	results := make([]bool, len(items))
+	for i, item := range items {
+		results[i] = bf.Test(item)
+	}
+	return results
+}
+
+// TestAndAdd はアイテムが既に存在する可能性があったかを調べてから追加する
+// ハッシュは1回だけ計算し、全てのビットが既に立っていたかを記録してからビットを立てる
+// 戻り値は追加前のTestの結果と同じ。TestとAddを別々に呼ぶより高速で、
+// 重複排除などの「初回だけ処理する」ロジックの基本操作になる
+func (bf *BloomFilter) TestAndAdd(item string) bool {
+	hashes := bf.getHashes([]byte(item))
+
+	present := true
+	for _, hash := range hashes {
+		if !bf.getBit(hash) {
+			present = false
+			bf.setBit(hash)
+		}
+	}
+
+	bf.numItems++
+	return present
+}
+
+// AddIfNew はアイテムが新しい場合だけnumItemsを増やして追加する
+// 少なくとも1つのビットが0から1に変わった場合に追加されたとみなしtrueを返す
+// 全てのビットが既に立っていた場合は重複の可能性が高いとしてnumItemsを増やさない
+// 偽陽性により、本当に新しいアイテムが重複と判定されてfalseになることがある
+func (bf *BloomFilter) AddIfNew(item string) bool {
+	hashes := bf.getHashes([]byte(item))
+
+	inserted := false
+	for _, hash := range hashes {
+		if !bf.getBit(hash) {
+			inserted = true
+			bf.setBit(hash)
+		}
+	}
+
+	if inserted {
+		bf.numItems++
+	}
+	return inserted
+}
+
+// ForEachSetBit は立っている各ビットの位置を昇順にfnへ渡す
+// 内部のビット配列を公開せずに走査できるため、デバッグや2つのフィルタの差分計算に使える
+func (bf *BloomFilter) ForEachSetBit(fn func(index int)) {
+	for w, word := range bf.bitArray {
+		// 最下位の立っているビットから順に取り出す
+		for word != 0 {
+			fn(w*64 + bits.TrailingZeros64(word))
+			word &= word - 1
+		}
+	}
+}
+
+// Capacity は作成時に想定したアイテム数を返す
+// NewBloomFilterWithHashesで作成した場合は、サイズとハッシュ関数の数から逆算した値になる
+func (bf *BloomFilter) Capacity() int {
+	return bf.capacity
+}
+
+// IsSaturated はフィルタが実質的に満杯かを判定
+// 追加されたアイテム数が容量を超えた場合、またはロードファクタが0.5を超えた場合にtrueを返す
+// （最適なパラメータではロードファクタは容量到達時に約0.5になる）
+// 監視側がフィルタを切り替えるタイミングの判断に使う
+func (bf *BloomFilter) IsSaturated() bool {
+	if bf.numItems > bf.capacity {
+		return true
+	}
+	return float64(bf.setBits)/float64(bf.size) > 0.5
+}
+
+// Reset はBloom Filterを空の状態に戻す
+// ビット配列を再割り当てせずに0クリアするため、同じパラメータのまま再利用できる
+func (bf *BloomFilter) Reset() {
+	clear(bf.bitArray)
+	bf.numItems = 0
+	bf.setBits = 0
+}
+
+// EstimateFalsePositiveRate は現在の偽陽性率を推定
+func (bf *BloomFilter) EstimateFalsePositiveRate() float64 {
+	if bf.numItems == 0 {
+		return 0.0
+	}
+
+	// 偽陽性率の理論値: (1 - e^(-kn/m))^k
+	// k: ハッシュ関数の数, n: アイテム数, m: ビット配列サイズ
+	k := float64(bf.numHashes)
+	n := float64(bf.numItems)
+	m := float64(bf.size)
+
+	return math.Pow(1.0-math.Exp(-k*n/m), k)
+}
+
+// FalsePositiveRateByFill は実際に立っているビットの割合から偽陽性率を推定
+// (setBits/size)^k はnumItemsに依存しないため、同じアイテムを重複して
+// 追加した場合や、numItemsが正確でない場合でも実際の状態を反映する
+func (bf *BloomFilter) FalsePositiveRateByFill() float64 {
+	fill := float64(bf.setBits) / float64(bf.size)
+	return math.Pow(fill, float64(bf.numHashes))
+}
+
+// ObservedFalsePositiveRate は実際に立っているビットの割合から求めた偽陽性率を返す
+// FalsePositiveRateByFillと同じ値で、理論値のEstimateFalsePositiveRateと対になる名前として提供する
+func (bf *BloomFilter) ObservedFalsePositiveRate() float64 {
+	return bf.FalsePositiveRateByFill()
+}
+
+// countSetBits はビット配列を走査して立っているビットの数を数える
+// ワード単位でまとめてビットを書き換えた後にsetBitsを再計算するために使う
+func (bf *BloomFilter) countSetBits() int {
+	setBits := 0
+	for _, word := range bf.bitArray {
+		setBits += bits.OnesCount64(word)
+	}
+	return setBits
+}
+
+// EstimateCardinality は追加された重複のないアイテム数を推定
+// numItemsはAddの呼び出し回数のため、同じアイテムを重複して追加すると過大になる
+// ここでは立っているビットの割合からの逆算 n ≈ -(m/k) * ln(1 - X/m) を使う
+// （m: ビット配列サイズ, k: ハッシュ関数の数, X: 立っているビット数）
+func (bf *BloomFilter) EstimateCardinality() int {
+	return cardinalityFromFill(bf.size, bf.numHashes, bf.setBits)
+}
+
+// cardinalityFromFill はsizeビット中setBitsビットが立っている状態からアイテム数を逆算
+func cardinalityFromFill(size, numHashes, setBits int) int {
+	if setBits == size {
+		// 全ビットが立っている場合は推定できないため上限として扱う
+		return size
+	}
+
+	m := float64(size)
+	k := float64(numHashes)
+	return int(math.Round(-m / k * math.Log(1.0-float64(setBits)/m)))
+}
+
+// BloomStats はBloom Filterの統計情報
+// 型付きのフィールドを持つため、型アサーションなしで扱えJSONにもそのまま変換できる
+type BloomStats struct {
+	Size          int     `json:"size"`           // ビット配列のサイズ
+	NumHashes     int     `json:"num_hashes"`     // ハッシュ関数の数
+	NumItems      int     `json:"num_items"`      // 追加されたアイテム数
+	SetBits       int     `json:"set_bits"`       // 立っているビット数
+	LoadFactor    float64 `json:"load_factor"`    // 立っているビットの割合
+	FalsePositive float64 `json:"false_positive"` // 推定偽陽性率
+}
+
+// StatsStruct はBloom Filterの統計情報をBloomStatsで返す
+func (bf *BloomFilter) StatsStruct() BloomStats {
+	setBits := bf.setBits
+
+	return BloomStats{
+		Size:          bf.size,
+		NumHashes:     bf.numHashes,
+		NumItems:      bf.numItems,
+		SetBits:       setBits,
+		LoadFactor:    float64(setBits) / float64(bf.size),
+		FalsePositive: bf.EstimateFalsePositiveRate(),
+	}
+}
+
+// Stats はBloom Filterの統計情報を返す
+// 後方互換のために残しているマップ版。新しいコードではStatsStructを使う
+func (bf *BloomFilter) Stats() map[string]interface{} {
+	stats := bf.StatsStruct()
+
+	return map[string]interface{}{
+		"size":           stats.Size,
+		"num_hashes":     stats.NumHashes,
+		"num_items":      stats.NumItems,
+		"set_bits":       stats.SetBits,
+		"load_factor":    stats.LoadFactor,
+		"false_positive": stats.FalsePositive,
+	}
+}
+
+// StatsJSON は統計情報をJSONで返す
+func (bf *BloomFilter) StatsJSON() ([]byte, error) {
+	return json.Marshal(bf.StatsStruct())
+}
+
+// incompatibility はotherが自身とビット演算できない理由を返す（可能なら空文字列）
+// ビット配列のサイズやハッシュ関数が一致しない場合は同じビットが同じアイテムを表さない
+// 指定されたハッシュ関数同士が同一かどうかまでは判定できないため、呼び出し側の責任とする
+func (bf *BloomFilter) incompatibility(other *BloomFilter) string {
+	switch {
+	case other.size != bf.size:
+		return fmt.Sprintf("has size %d, expected %d", other.size, bf.size)
+	case other.numHashes != bf.numHashes:
+		return fmt.Sprintf("has num hashes %d, expected %d", other.numHashes, bf.numHashes)
+	case (other.hashFuncs == nil) != (bf.hashFuncs == nil):
+		return "uses a different hashing scheme"
+	case other.scheme != bf.scheme:
+		return "uses a different hashing scheme"
+	case other.seed != bf.seed:
+		return fmt.Sprintf("has seed %d, expected %d", other.seed, bf.seed)
+	}
+	return ""
+}
+
+// checkCompatible は2つのBloom Filterのビット演算が可能か確認
+func (bf *BloomFilter) checkCompatible(other *BloomFilter) error {
+	if other == nil {
+		return errors.New("bloom filter is nil")
+	}
+	if reason := bf.incompatibility(other); reason != "" {
+		return errors.New("bloom filter " + reason)
+	}
+	return nil
+}
+
+// emptyCopy は同じパラメータを持つ空のBloom Filterを作成
+func (bf *BloomFilter) emptyCopy() *BloomFilter {
+	return &BloomFilter{
+		bitArray:  make([]uint64, len(bf.bitArray)),
+		size:      bf.size,
+		hashFuncs: bf.freshHashes(),
+		newHashes: bf.newHashes,
+		numHashes: bf.numHashes,
+		numItems:  0,
+		seed:      bf.seed,
+		scheme:    bf.scheme,
+		capacity:  bf.capacity,
+	}
+}
+
+// freshHashes はnewHashesから新しいhash.Hashのインスタンスを作成
+// ダブルハッシュ法（newHashesがnil）の場合はnilを返す
+func (bf *BloomFilter) freshHashes() []hash.Hash {
+	if bf.newHashes == nil {
+		return nil
+	}
+	hashFuncs := make([]hash.Hash, len(bf.newHashes))
+	for i, newHash := range bf.newHashes {
+		hashFuncs[i] = newHash()
+	}
+	return hashFuncs
+}
+
+// Clone はBloom Filterの独立したコピーを作成
+// ビット配列を新しいスライスに複製するため、コピーへの変更は元のフィルタに影響しない
+// 投機的にアイテムを追加し、不要になれば元のフィルタに戻すといった用途に使う
+// NewBloomFilterWithHashesで作成したフィルタでは、指定された関数から新しいhash.Hashを作り直すため
+// 元のフィルタとコピーを別々のゴルーチンで使用できる
+func (bf *BloomFilter) Clone() *BloomFilter {
+	clone := bf.emptyCopy()
+	copy(clone.bitArray, bf.bitArray)
+	clone.numItems = bf.numItems
+	clone.setBits = bf.setBits
+	return clone
+}
+
+// Equals は2つのBloom Filterが同じ状態かをビット単位で比較
+// パラメータ（size, numHashes, ハッシュ法, シード）と全てのビットが一致する場合にtrueを返す
+// 異なる追加順序や重複した追加でも同じビット配列になり得るため、numItemsは比較しない
+func (bf *BloomFilter) Equals(other *BloomFilter) bool {
+	if other == nil || bf.incompatibility(other) != "" {
+		return false
+	}
+
+	for i := range bf.bitArray {
+		if bf.bitArray[i] != other.bitArray[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Compress はビット配列をfactor分の1に縮小した新しいBloom Filterを返す
+// 縮小後のビットjは、元のビットのうち i % (size/factor) == j となるものが
+// 一つでも立っていれば立てる。どのハッシュ法もインデックスを (ハッシュ値 % size) で
+// 求めており、size/factorがsizeを割り切る場合 (h % size) % (size/factor) == h % (size/factor)
+// となるため、縮小後のフィルタでも元のアイテムは必ず陽性になる
+// sizeがfactorで割り切れない場合はこの関係が成り立たないためエラーを返す
+// 縮小によりビットが密になるため、偽陽性率は元のフィルタより高くなる
+func (bf *BloomFilter) Compress(factor int) (*BloomFilter, error) {
+	if factor < 1 {
+		return nil, fmt.Errorf("compress factor must be positive, got %d", factor)
+	}
+	if bf.size%factor != 0 {
+		return nil, fmt.Errorf("size %d is not divisible by compress factor %d", bf.size, factor)
+	}
+
+	newSize := bf.size / factor
+	result := bf.emptyCopy()
+	result.size = newSize
+	result.bitArray = make([]uint64, numWords(newSize))
+	bf.ForEachSetBit(func(index int) {
+		result.setBit(index % newSize)
+	})
+	result.numItems = bf.numItems
+	result.capacity = max(bf.capacity/factor, 1)
+
+	return result, nil
+}
+
+// Union はotherのビット配列を自身にORで取り込む（和集合）
+// sizeまたはnumHashesが異なる場合は互換性がないためエラーを返す
+// numItemsは両者の合計になるが、両方に追加されたアイテムを二重に数えるため
+// その後のEstimateFalsePositiveRateは近似値になる
+func (bf *BloomFilter) Union(other *BloomFilter) error {
+	if err := bf.checkCompatible(other); err != nil {
+		return err
+	}
+
+	for i := range bf.bitArray {
+		bf.bitArray[i] |= other.bitArray[i]
+	}
+	bf.numItems += other.numItems
+	bf.setBits = bf.countSetBits()
+
+	return nil
+}
+
+// MergeRange はotherのビット配列のうち[start, end)の範囲を自身にORで取り込む
+// 1つの論理的なフィルタをビットの範囲ごとのシャードに分けて並列に再構築し、
+// 各シャードが担当する範囲だけを書き戻す用途に使う
+// 範囲外のビットとnumItemsは変更しない（範囲ごとのアイテム数は分からないため）
+// 互換性がない場合や、範囲が0 <= start <= end <= sizeを満たさない場合はエラーを返す
+func (bf *BloomFilter) MergeRange(other *BloomFilter, start, end int) error {
+	if err := bf.checkCompatible(other); err != nil {
+		return err
+	}
+	if start < 0 || end > bf.size || start > end {
+		return fmt.Errorf("bit range [%d, %d) is out of bounds for size %d", start, end, bf.size)
+	}
+
+	for w := start / 64; w*64 < end; w++ {
+		// ワード内で範囲に含まれるビットだけを残すマスク
+		mask := ^uint64(0)
+		if lo := start - w*64; lo > 0 {
+			mask &= ^uint64(0) << lo
+		}
+		if hi := end - w*64; hi < 64 {
+			mask &= ^uint64(0) >> (64 - hi)
+		}
+		bf.bitArray[w] |= other.bitArray[w] & mask
+	}
+	bf.setBits = bf.countSetBits()
+
+	return nil
+}
+
+// Merge は複数のBloom FilterをORで統合した新しいBloom Filterを返す
+// 全てのフィルタがfilters[0]と同じsizeとnumHashesを持つ必要があり、
+// 最初に一致しなかったフィルタの番号と異なるフィールドをエラーで返す
+// 入力はどれも変更しない
+func Merge(filters ...*BloomFilter) (*BloomFilter, error) {
+	if len(filters) == 0 {
+		return nil, errors.New("merge requires at least one bloom filter")
+	}
+	if filters[0] == nil {
+		return nil, errors.New("filter[0] is nil")
+	}
+
+	first := filters[0]
+	for i, f := range filters[1:] {
+		if f == nil {
+			return nil, fmt.Errorf("filter[%d] is nil", i+1)
+		}
+		if reason := first.incompatibility(f); reason != "" {
+			return nil, fmt.Errorf("filter[%d] %s", i+1, reason)
+		}
+	}
+
+	result := first.emptyCopy()
+	for _, f := range filters {
+		for i := range result.bitArray {
+			result.bitArray[i] |= f.bitArray[i]
+		}
+		result.numItems += f.numItems
+	}
+	result.setBits = result.countSetBits()
+
+	return result, nil
+}
+
+// Or は2つのBloom FilterのビットごとのORを新しいBloom Filterとして返す
+// 結果はどちらかに追加されたアイテムを含む（和集合）。入力はどちらも変更しない
+// numItemsは両者の合計になるため、重複分を二重に数えることに注意
+func (bf *BloomFilter) Or(other *BloomFilter) (*BloomFilter, error) {
+	if err := bf.checkCompatible(other); err != nil {
+		return nil, err
+	}
+
+	result := bf.emptyCopy()
+	for i := range result.bitArray {
+		result.bitArray[i] = bf.bitArray[i] | other.bitArray[i]
+	}
+	result.setBits = result.countSetBits()
+	result.numItems = bf.numItems + other.numItems
+
+	return result, nil
+}
+
+// And は2つのBloom FilterのビットごとのANDを新しいBloom Filterとして返す
+// 結果は両方に追加された可能性のあるアイテムを表す（積集合の近似）。入力はどちらも変更しない
+// numItemsは立っているビット数からの推定値に設定する
+func (bf *BloomFilter) And(other *BloomFilter) (*BloomFilter, error) {
+	if err := bf.checkCompatible(other); err != nil {
+		return nil, err
+	}
+
+	result := bf.emptyCopy()
+	for i := range result.bitArray {
+		result.bitArray[i] = bf.bitArray[i] & other.bitArray[i]
+	}
+	result.setBits = result.countSetBits()
+	result.numItems = result.EstimateCardinality()
+
+	return result, nil
+}
+
+// Intersect は2つのBloom Filterの積集合を近似する新しいBloom Filterを返す
+// Andと同じく入力はどちらも変更せず、互換性がない場合はエラーを返す
+// 片方のアイテムのビットがもう片方の別のアイテムのビットと重なって残ることがあるため、
+// 結果の偽陽性率はそれぞれの入力の偽陽性率より高くなり得る
+// numItemsは結果のビット配列から推定した値になる
+func (bf *BloomFilter) Intersect(other *BloomFilter) (*BloomFilter, error) {
+	return bf.And(other)
+}
+
+// Difference はaに追加されbには追加されていない可能性のあるアイテムを表すフィルタを返す
+// ビットごとに a AND NOT b を計算する。aとbは同じパラメータである必要がある
+// 結果は候補のキーを判定するためのフィルタとしてのみ使える（差集合の列挙はできない）
+// 両方に追加されたキーは必ず陰性になる。一方、ビットを消すとbの別のキーと共有していた
+// ビットも消えるため、aにしか追加されていないキーでも陰性になることがある（偽陰性が起こり得る）
+// bにはaに追加されていないアイテムも含まれ得るため、numItemsはAndと同様に
+// 結果のビット配列からの推定値に設定する
+// リクエストの Difference(a, b) *BloomFilter と異なりエラーも返すのは意図的で、
+// OrやAndと同じくnilやパラメータの異なるフィルタを渡した場合にエラーとする
+func Difference(a, b *BloomFilter) (*BloomFilter, error) {
+	if a == nil {
+		return nil, errors.New("bloom filter is nil")
+	}
+	if err := a.checkCompatible(b); err != nil {
+		return nil, err
+	}
+
+	result := a.emptyCopy()
+	for i := range result.bitArray {
+		result.bitArray[i] = a.bitArray[i] &^ b.bitArray[i]
+	}
+	result.setBits = result.countSetBits()
+	result.numItems = result.EstimateCardinality()
+
+	return result, nil
+}
+
+// HashCorrelation はハッシュ関数間の相関を計測
+// サンプルの各キーについて、異なる2つのハッシュ関数が同じインデックスを
+// 返したペアの割合を返す。独立なハッシュ関数なら約 1/size になり、
+// 0に近いほど良い（相関が高いと偽陽性率が悪化する）
+func (bf *BloomFilter) HashCorrelation(sampleKeys []string) float64 {
+	if bf.numHashes < 2 || len(sampleKeys) == 0 {
+		return 0.0
+	}
+
+	collisions := 0
+	pairs := 0
+	for _, key := range sampleKeys {
+		hashes := bf.getHashes([]byte(key))
+		for i := 0; i < len(hashes); i++ {
+			for j := i + 1; j < len(hashes); j++ {
+				if hashes[i] == hashes[j] {
+					collisions++
+				}
+				pairs++
+			}
+		}
+	}
+
+	return float64(collisions) / float64(pairs)
+}
+
+// HashCollisions はitemのnumHashes個のインデックスのうち、他のハッシュ関数と重複したものの数を返す
+// 例えばインデックスが [3, 7, 3, 3] なら、1つ目以外の3が重複なので2になる
+// 重複したハッシュ関数は新しいビットを立てないため、実質的なハッシュ関数の数が減る
+// numHashesを調整するための診断用で、所属判定には影響しない
+func (bf *BloomFilter) HashCollisions(item string) int {
+	hashes := bf.getHashes([]byte(item))
+
+	seen := make(map[int]struct{}, len(hashes))
+	for _, hash := range hashes {
+		seen[hash] = struct{}{}
+	}
+
+	return len(hashes) - len(seen)
+}
+
+// AverageSelfCollision はサンプルの各キーについてのHashCollisionsの平均を返す
+// サンプルが空の場合は0を返す
+func (bf *BloomFilter) AverageSelfCollision(samples []string) float64 {
+	if len(samples) == 0 {
+		return 0.0
+	}
+
+	total := 0
+	for _, item := range samples {
+		total += bf.HashCollisions(item)
+	}
+
+	return float64(total) / float64(len(samples))
+}
+
+// PrintStats は統計情報を表示
+func (bf *BloomFilter) PrintStats() {
+	stats := bf.Stats()
+	fmt.Println("=== Bloom Filter Statistics ===")
+	fmt.Printf("Size: %d bits\n", stats["size"])
+	fmt.Printf("Hash functions: %d\n", stats["num_hashes"])
+	fmt.Printf("Items added: %d\n", stats["num_items"])
+	fmt.Printf("Set bits: %d\n", stats["set_bits"])
+	fmt.Printf("Load factor: %.3f\n", stats["load_factor"])
+	fmt.Printf("Estimated false positive rate: %.6f (%.4f%%)\n",
+		stats["false_positive"], stats["false_positive"].(float64)*100)
+}
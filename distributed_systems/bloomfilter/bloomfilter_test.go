@@ -0,0 +1,768 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/json"
+	"hash"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestCloneIsIndependent(t *testing.T) {
+	bf := NewBloomFilter(1000, 0.01)
+	bf.Add("apple")
+
+	clone := bf.Clone()
+	if !clone.Equals(bf) {
+		t.Fatal("clone differs from the original")
+	}
+	for i := range 100 {
+		clone.Add("speculative_" + strconv.Itoa(i))
+	}
+
+	// コピーへの追加は元のフィルタに影響しない
+	if bf.numItems != 1 {
+		t.Errorf("original numItems = %d after mutating clone, want 1", bf.numItems)
+	}
+	if bf.Test("speculative_0") {
+		t.Error("original reports an item added only to the clone")
+	}
+	if !clone.Test("apple") {
+		t.Error("clone lost an item present at clone time")
+	}
+}
+
+func TestCloneWithHashesGetsFreshInstances(t *testing.T) {
+	bf, err := NewBloomFilterWithHashes(1<<12, 3, []func() hash.Hash{md5.New, sha1.New, sha256.New})
+	if err != nil {
+		t.Fatalf("NewBloomFilterWithHashes: %v", err)
+	}
+	clone := bf.Clone()
+	for i := range bf.hashFuncs {
+		if bf.hashFuncs[i] == clone.hashFuncs[i] {
+			t.Fatalf("hash function %d is shared between original and clone", i)
+		}
+	}
+
+	// 元とコピーを別々のゴルーチンで使用しても結果が混ざらない（-raceで検出される）
+	var wg sync.WaitGroup
+	for _, f := range []*BloomFilter{bf, clone} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				f.Add("item_" + strconv.Itoa(i))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !bf.Equals(clone) {
+		t.Error("original and clone diverged after identical concurrent adds")
+	}
+	for i := range 1000 {
+		if item := "item_" + strconv.Itoa(i); !bf.Test(item) {
+			t.Fatalf("Test(%q) = false after Add", item)
+		}
+	}
+}
+
+func TestSetBitsMatchesRecount(t *testing.T) {
+	check := func(t *testing.T, bf *BloomFilter, when string) {
+		t.Helper()
+		if got, want := bf.setBits, bf.countSetBits(); got != want {
+			t.Fatalf("%s: setBits = %d, recount = %d", when, got, want)
+		}
+	}
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	bf := NewBloomFilter(500, 0.01)
+	other := NewBloomFilter(500, 0.01)
+	for i := range 2000 {
+		// 重複を含む任意の順序で追加する
+		bf.Add("item_" + strconv.Itoa(rng.IntN(800)))
+		other.Add("other_" + strconv.Itoa(rng.IntN(800)))
+		if i%97 == 0 {
+			check(t, bf, "after Add #"+strconv.Itoa(i))
+		}
+	}
+	check(t, bf, "after adds")
+
+	if err := bf.Union(other); err != nil {
+		t.Fatalf("Union: %v", err)
+	}
+	check(t, bf, "after Union")
+
+	var buf bytes.Buffer
+	if _, err := bf.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	var loaded BloomFilter
+	if _, err := loaded.ReadFrom(&buf); err != nil {
+		t.Fatalf("ReadFrom: %v", err)
+	}
+	if loaded.setBits != bf.setBits {
+		t.Errorf("setBits after round trip = %d, want %d", loaded.setBits, bf.setBits)
+	}
+	check(t, &loaded, "after ReadFrom")
+
+	bf.Reset()
+	if bf.setBits != 0 {
+		t.Errorf("setBits after Reset = %d, want 0", bf.setBits)
+	}
+	check(t, bf, "after Reset")
+}
+
+// benchSizes はベンチマークするフィルタの想定アイテム数
+var benchSizes = []int{1_000, 100_000, 1_000_000}
+
+// benchKeys はn個の異なるキーを事前に作成（計測中にキーの生成コストを含めないため）
+func benchKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "item_" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+// filledFilter はkeysをすべて追加したフィルタを作成
+func filledFilter(keys []string) *BloomFilter {
+	bf := NewBloomFilter(len(keys), 0.01)
+	for _, key := range keys {
+		bf.Add(key)
+	}
+	return bf
+}
+
+func BenchmarkBloomAdd(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			keys := benchKeys(n)
+			bf := NewBloomFilter(n, 0.01)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				bf.Add(keys[i%len(keys)])
+			}
+		})
+	}
+}
+
+func BenchmarkBloomTest(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			keys := benchKeys(n)
+			bf := filledFilter(keys)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				bf.Test(keys[i%len(keys)])
+			}
+		})
+	}
+}
+
+// Statsは立っているビット数を増分で管理しているため、フィルタの大きさによらず一定時間で返る
+func BenchmarkBloomStats(b *testing.B) {
+	for _, n := range benchSizes {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			bf := filledFilter(benchKeys(n))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				bf.Stats()
+			}
+		})
+	}
+}
+
+// legacyHashes は以前のcreateHashFunctionsと同じく md5/sha1/sha256 を順に繰り返すハッシュ関数を返す
+func legacyHashes(numHashes int) []func() hash.Hash {
+	cycle := []func() hash.Hash{md5.New, sha1.New, sha256.New}
+	hashes := make([]func() hash.Hash, numHashes)
+	for i := range hashes {
+		hashes[i] = cycle[i%len(cycle)]
+	}
+	return hashes
+}
+
+func TestHashCorrelationLegacyVsDoubleHashing(t *testing.T) {
+	doubleHashing := NewBloomFilter(1000, 0.01)
+	legacy, err := NewBloomFilterWithHashes(doubleHashing.size, doubleHashing.numHashes, legacyHashes(doubleHashing.numHashes))
+	if err != nil {
+		t.Fatalf("NewBloomFilterWithHashes: %v", err)
+	}
+	keys := benchKeys(1000)
+
+	legacyCorrelation := legacy.HashCorrelation(keys)
+	doubleCorrelation := doubleHashing.HashCorrelation(keys)
+	if legacyCorrelation <= doubleCorrelation {
+		t.Errorf("legacy correlation %.4f is not higher than double hashing %.4f", legacyCorrelation, doubleCorrelation)
+	}
+	// 独立なハッシュ関数なら約 1/size になる
+	if limit := 10.0 / float64(doubleHashing.size); doubleCorrelation > limit {
+		t.Errorf("double hashing correlation = %.6f, want <= %.6f", doubleCorrelation, limit)
+	}
+}
+
+// pairOfFilters は一部のアイテムを共有する同じパラメータの2つのフィルタを作成
+func pairOfFilters() (a, b *BloomFilter) {
+	a, b = NewBloomFilter(200, 0.01), NewBloomFilter(200, 0.01)
+	for i := range 100 {
+		a.Add("a_" + strconv.Itoa(i))
+		b.Add("b_" + strconv.Itoa(i))
+		if i%2 == 0 {
+			a.Add("shared_" + strconv.Itoa(i))
+			b.Add("shared_" + strconv.Itoa(i))
+		}
+	}
+	return a, b
+}
+
+func TestOrAndLeaveInputsUnchanged(t *testing.T) {
+	a, b := pairOfFilters()
+	origA, origB := a.Clone(), b.Clone()
+
+	or, err := a.Or(b)
+	if err != nil {
+		t.Fatalf("Or: %v", err)
+	}
+	and, err := a.And(b)
+	if err != nil {
+		t.Fatalf("And: %v", err)
+	}
+	if !a.Equals(origA) || a.numItems != origA.numItems {
+		t.Error("Or/And modified the receiver")
+	}
+	if !b.Equals(origB) || b.numItems != origB.numItems {
+		t.Error("Or/And modified the argument")
+	}
+
+	// Orは破壊的なUnionと同じ結果になる
+	union := a.Clone()
+	if err := union.Union(b); err != nil {
+		t.Fatalf("Union: %v", err)
+	}
+	if !or.Equals(union) || or.numItems != union.numItems {
+		t.Error("Or differs from Union")
+	}
+
+	intersect, err := a.Intersect(b)
+	if err != nil {
+		t.Fatalf("Intersect: %v", err)
+	}
+	if !and.Equals(intersect) {
+		t.Error("And differs from Intersect")
+	}
+	for i := range and.bitArray {
+		if and.bitArray[i] != a.bitArray[i]&b.bitArray[i] {
+			t.Fatalf("And word %d = %x, want %x", i, and.bitArray[i], a.bitArray[i]&b.bitArray[i])
+		}
+	}
+	for i := 0; i < 100; i += 2 {
+		if item := "shared_" + strconv.Itoa(i); !and.Test(item) {
+			t.Errorf("And lost shared item %q", item)
+		}
+	}
+}
+
+func TestOrAndRejectIncompatibleFilters(t *testing.T) {
+	a := NewBloomFilter(200, 0.01)
+	if _, err := a.Or(NewBloomFilter(300, 0.01)); err == nil {
+		t.Error("Or with a different size succeeded, want error")
+	}
+	if _, err := a.And(NewBloomFilterMurmur(200, 0.01)); err == nil {
+		t.Error("And with a different hashing scheme succeeded, want error")
+	}
+	if _, err := a.And(nil); err == nil {
+		t.Error("And(nil) succeeded, want error")
+	}
+}
+
+// measuredFalsePositiveRate は追加していないn個のキーを判定して実際の偽陽性率を求める
+func measuredFalsePositiveRate(bf *BloomFilter, n int) float64 {
+	positives := 0
+	for i := range n {
+		if bf.Test("absent_" + strconv.Itoa(i)) {
+			positives++
+		}
+	}
+	return float64(positives) / float64(n)
+}
+
+func TestFalsePositiveRateByFillIgnoresDuplicates(t *testing.T) {
+	bf := NewBloomFilter(1000, 0.01)
+	for range 20 {
+		for i := range 500 {
+			bf.Add("item_" + strconv.Itoa(i))
+		}
+	}
+
+	// 500個の異なるアイテムだけを1回ずつ追加したフィルタと同じ状態になる
+	unique := NewBloomFilter(1000, 0.01)
+	for i := range 500 {
+		unique.Add("item_" + strconv.Itoa(i))
+	}
+	if got, want := bf.FalsePositiveRateByFill(), unique.FalsePositiveRateByFill(); got != want {
+		t.Errorf("FalsePositiveRateByFill() = %g with duplicates, want %g", got, want)
+	}
+
+	// numItemsは重複分を数えるため、アイテム数による推定は大きく外れる
+	byCount, byFill := bf.EstimateFalsePositiveRate(), bf.FalsePositiveRateByFill()
+	measured := measuredFalsePositiveRate(bf, 100_000)
+	if byCount < 10*byFill {
+		t.Errorf("item-count estimate %g is not inflated by duplicates (fill estimate %g)", byCount, byFill)
+	}
+	if diff := byFill - measured; diff > 0.005 || diff < -0.005 {
+		t.Errorf("fill estimate %g is far from measured rate %g", byFill, measured)
+	}
+}
+
+func TestObservedFalsePositiveRateDivergesWithDuplicates(t *testing.T) {
+	bf := NewBloomFilter(1000, 0.01)
+	for i := range 500 {
+		bf.Add("item_" + strconv.Itoa(i))
+	}
+
+	// 重複がなければ2つの推定はほぼ一致する
+	observed, estimated := bf.ObservedFalsePositiveRate(), bf.EstimateFalsePositiveRate()
+	if math.Abs(observed-estimated) > estimated/2 {
+		t.Errorf("without duplicates: observed %g, estimated %g, want close", observed, estimated)
+	}
+
+	// 同じアイテムを追加し直すとnumItemsだけが増え、理論値だけが大きくなる
+	for range 10 {
+		for i := range 500 {
+			bf.Add("item_" + strconv.Itoa(i))
+		}
+	}
+	if got := bf.ObservedFalsePositiveRate(); got != observed {
+		t.Errorf("observed rate changed from %g to %g by duplicates", observed, got)
+	}
+	if got := bf.EstimateFalsePositiveRate(); got < 10*observed {
+		t.Errorf("with duplicates: estimated %g, observed %g, want estimate to diverge", got, observed)
+	}
+}
+
+func TestDifferenceKeepsOnlyKeysMissingFromB(t *testing.T) {
+	// bの充填率が低いほど、aにしかないキーのビットがbと重なりにくい
+	a, b := NewBloomFilter(10_000, 0.01), NewBloomFilter(10_000, 0.01)
+	for i := range 100 {
+		key := "shared_" + strconv.Itoa(i)
+		a.Add(key)
+		b.Add(key)
+	}
+	for i := range 100 {
+		a.Add("new_" + strconv.Itoa(i))
+	}
+
+	diff, err := Difference(a, b)
+	if err != nil {
+		t.Fatalf("Difference: %v", err)
+	}
+
+	// aにしかないキーは、ビットがbの別のキーと共有されていない限り陽性になる
+	positives := 0
+	for i := range 100 {
+		if diff.Test("new_" + strconv.Itoa(i)) {
+			positives++
+		}
+	}
+	if positives < 85 {
+		t.Errorf("%d of 100 keys only in a test positive in the difference, want >= 85", positives)
+	}
+	// 両方にあるキーは全てのビットが消えるため必ず陰性になる
+	for i := range 100 {
+		if key := "shared_" + strconv.Itoa(i); diff.Test(key) {
+			t.Fatalf("shared key %q tests positive in the difference", key)
+		}
+	}
+
+	// numItemsはa.numItems-b.numItemsではなく、結果のビット配列からの推定値
+	if got, want := diff.numItems, diff.EstimateCardinality(); got != want {
+		t.Errorf("numItems = %d, want estimate %d", got, want)
+	}
+	if diff.numItems < 80 || diff.numItems > 100 {
+		t.Errorf("numItems = %d, want about 100 keys only in a", diff.numItems)
+	}
+
+	if _, err := Difference(a, NewBloomFilter(10, 0.01)); err == nil {
+		t.Error("Difference of incompatible filters succeeded, want error")
+	}
+	if _, err := Difference(nil, b); err == nil {
+		t.Error("Difference(nil, b) succeeded, want error")
+	}
+}
+
+func TestMergeRangeRebuildsFromDisjointShards(t *testing.T) {
+	full := filledFilter(benchKeys(1000))
+
+	// ワード境界に揃っていない範囲も含めて、シャードごとに書き戻す
+	rebuilt := NewBloomFilter(1000, 0.01)
+	bounds := []int{0, 1, 100, 64 * 3, 1000, 5000, full.size}
+	for i := 1; i < len(bounds); i++ {
+		if err := rebuilt.MergeRange(full, bounds[i-1], bounds[i]); err != nil {
+			t.Fatalf("MergeRange(%d, %d): %v", bounds[i-1], bounds[i], err)
+		}
+	}
+	if !rebuilt.Equals(full) {
+		t.Error("filter rebuilt from disjoint ranges differs from the original")
+	}
+	if rebuilt.setBits != full.setBits {
+		t.Errorf("setBits = %d, want %d", rebuilt.setBits, full.setBits)
+	}
+
+	// 範囲外のビットは変更しない
+	partial := NewBloomFilter(1000, 0.01)
+	if err := partial.MergeRange(full, 100, 200); err != nil {
+		t.Fatalf("MergeRange(100, 200): %v", err)
+	}
+	for i := 0; i < full.size; i++ {
+		want := i >= 100 && i < 200 && full.getBit(i)
+		if partial.getBit(i) != want {
+			t.Fatalf("bit %d = %v, want %v", i, partial.getBit(i), want)
+		}
+	}
+}
+
+func TestMergeRangeRejectsInvalidInput(t *testing.T) {
+	bf := NewBloomFilter(1000, 0.01)
+	other := NewBloomFilter(1000, 0.01)
+	tests := []struct {
+		name       string
+		other      *BloomFilter
+		start, end int
+	}{
+		{"negative start", other, -1, 10},
+		{"end past size", other, 0, bf.size + 1},
+		{"start after end", other, 20, 10},
+		{"different size", NewBloomFilter(2000, 0.01), 0, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := bf.MergeRange(tt.other, tt.start, tt.end); err == nil {
+				t.Errorf("MergeRange(%d, %d) = nil, want error", tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestStatsJSONHasStatsKeys(t *testing.T) {
+	bf := NewBloomFilter(1000, 0.01)
+	bf.Add("apple")
+
+	data, err := bf.StatsJSON()
+	if err != nil {
+		t.Fatalf("StatsJSON: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("StatsJSON output is not a JSON object: %v", err)
+	}
+	stats := bf.Stats()
+	if len(got) != len(stats) {
+		t.Errorf("StatsJSON has %d keys, Stats has %d", len(got), len(stats))
+	}
+	for key := range stats {
+		if _, ok := got[key]; !ok {
+			t.Errorf("StatsJSON is missing key %q", key)
+		}
+	}
+	if got["num_items"] != 1.0 || got["size"] != float64(bf.size) {
+		t.Errorf("StatsJSON = %s, want num_items 1 and size %d", data, bf.size)
+	}
+}
+
+// BenchmarkTestHashing は以前の md5/sha1/sha256 を順に使う方式とダブルハッシュ法のTestを比較する
+func BenchmarkTestHashing(b *testing.B) {
+	keys := benchKeys(10_000)
+	doubleHashing := filledFilter(keys)
+	legacy, err := NewBloomFilterWithHashes(doubleHashing.size, doubleHashing.numHashes, legacyHashes(doubleHashing.numHashes))
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, key := range keys {
+		legacy.Add(key)
+	}
+
+	for _, bc := range []struct {
+		name string
+		bf   *BloomFilter
+	}{
+		{"legacy", legacy},
+		{"double-hashing", doubleHashing},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bc.bf.Test(keys[i%len(keys)])
+			}
+		})
+	}
+}
+
+// BenchmarkAddBytes はネットワークから受け取った[]byteを文字列に変換して追加する場合と
+// AddBytesでそのまま追加する場合のアロケーションを比較する
+func BenchmarkAddBytes(b *testing.B) {
+	keys := make([][]byte, 10_000)
+	for i := range keys {
+		keys[i] = []byte("item_" + strconv.Itoa(i))
+	}
+
+	b.Run("string-conversion", func(b *testing.B) {
+		bf := NewBloomFilter(len(keys), 0.01)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			bf.Add(string(keys[i%len(keys)]))
+		}
+	})
+	b.Run("bytes", func(b *testing.B) {
+		bf := NewBloomFilter(len(keys), 0.01)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			bf.AddBytes(keys[i%len(keys)])
+		}
+	})
+}
+
+func TestLargeFilterFalsePositiveRateNearTarget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("fills a filter with a million items")
+	}
+	const target = 0.01
+	keys := benchKeys(1_000_000)
+	bf := filledFilter(keys)
+
+	if rate := measuredFalsePositiveRate(bf, 200_000); rate > 1.2*target || rate < 0.8*target {
+		t.Errorf("measured false positive rate %.4f, want within 20%% of %.2f", rate, target)
+	}
+}
+
+func TestIndicesUseHighBitsOfLargeSizes(t *testing.T) {
+	// 32ビットを超えるサイズでは、ダイジェストの先頭4バイトだけでは上位のインデックスに届かない
+	// hashIndicesはビット配列を使わないため、実際に確保せずに検証できる
+	if strconv.IntSize < 64 {
+		t.Skip("needs 64-bit int")
+	}
+	var size64 uint64 = 1 << 34
+	size := int(size64) // 32ビット環境でも型検査を通すため実行時に変換する
+	high := 0
+	total := 0
+	for _, key := range benchKeys(1000) {
+		for _, idx := range hashIndices([]byte(key), 7, size, 0) {
+			if uint64(idx) >= 1<<32 {
+				high++
+			}
+			total++
+		}
+	}
+	// 一様なら3/4のインデックスが2^32以上になる
+	if frac := float64(high) / float64(total); frac < 0.7 || frac > 0.8 {
+		t.Errorf("%.3f of indices are >= 2^32 for size 2^34, want about 0.75", frac)
+	}
+}
+
+func TestResetClearsItemsAndKeepsParameters(t *testing.T) {
+	bf := NewBloomFilter(1000, 0.01)
+	keys := benchKeys(500)
+	for _, key := range keys {
+		bf.Add(key)
+	}
+	size, numHashes, words := bf.size, bf.numHashes, &bf.bitArray[0]
+
+	bf.Reset()
+	for _, key := range keys {
+		if bf.Test(key) {
+			t.Fatalf("Test(%q) = true after Reset", key)
+		}
+	}
+	if bf.numItems != 0 {
+		t.Errorf("numItems = %d after Reset, want 0", bf.numItems)
+	}
+	if bf.size != size || bf.numHashes != numHashes {
+		t.Errorf("Reset changed parameters to size=%d numHashes=%d", bf.size, bf.numHashes)
+	}
+	if &bf.bitArray[0] != words {
+		t.Error("Reset reallocated the bit array")
+	}
+
+	// 同じフィルタを再利用できる
+	bf.Add("again")
+	if !bf.Test("again") {
+		t.Error("Test(again) = false after reusing a reset filter")
+	}
+}
+
+func TestSeedsGiveDifferentBitPatterns(t *testing.T) {
+	keys := benchKeys(100)
+	build := func(seed uint64) *BloomFilter {
+		bf := NewBloomFilterWithSeed(1000, 0.01, seed)
+		for _, key := range keys {
+			bf.Add(key)
+		}
+		return bf
+	}
+
+	a, again, b := build(1), build(1), build(2)
+	if !a.Equals(again) {
+		t.Error("same seed produced different bit patterns")
+	}
+	if slices.Equal(a.bitArray, b.bitArray) {
+		t.Error("different seeds produced identical bit patterns")
+	}
+	// シード0はシードなしのフィルタと同じ配置になる
+	unseeded := NewBloomFilter(1000, 0.01)
+	for _, key := range keys {
+		unseeded.Add(key)
+	}
+	if !slices.Equal(build(0).bitArray, unseeded.bitArray) {
+		t.Error("seed 0 differs from an unseeded filter")
+	}
+	for _, key := range keys {
+		if !b.Test(key) {
+			t.Fatalf("Test(%q) = false on a seeded filter", key)
+		}
+	}
+	if err := a.Union(b); err == nil {
+		t.Error("Union of filters with different seeds succeeded, want error")
+	}
+}
+
+func TestEstimateCardinalityTracksDistinctItems(t *testing.T) {
+	bf := NewBloomFilter(10_000, 0.01)
+	const distinct = 2000
+	for range 10 {
+		for i := range distinct {
+			bf.Add("item_" + strconv.Itoa(i))
+		}
+	}
+
+	if bf.numItems != 10*distinct {
+		t.Fatalf("numItems = %d, want %d", bf.numItems, 10*distinct)
+	}
+	// 重複した追加はビットを増やさないため、推定値はAddの回数ではなく異なるアイテム数に近い
+	if est := bf.EstimateCardinality(); est < distinct*95/100 || est > distinct*105/100 {
+		t.Errorf("EstimateCardinality() = %d, want within 5%% of %d", est, distinct)
+	}
+	if est := NewBloomFilter(100, 0.01).EstimateCardinality(); est != 0 {
+		t.Errorf("EstimateCardinality() of an empty filter = %d, want 0", est)
+	}
+}
+
+func TestEqualsComparesBits(t *testing.T) {
+	a, b := NewBloomFilter(1000, 0.01), NewBloomFilter(1000, 0.01)
+	a.Add("apple")
+	a.Add("banana")
+	// 追加順序と重複が異なっても同じビット配列なら等しい（numItemsは比較しない）
+	b.Add("banana")
+	b.Add("apple")
+	b.Add("apple")
+	if !a.Equals(b) || !b.Equals(a) {
+		t.Error("filters with the same bits are not equal")
+	}
+
+	// 1ビットだけ異なる
+	for i := 0; i < b.size; i++ {
+		if !b.getBit(i) {
+			b.setBit(i)
+			break
+		}
+	}
+	if a.Equals(b) {
+		t.Error("filters differing in one bit are equal")
+	}
+
+	if a.Equals(NewBloomFilter(2000, 0.01)) {
+		t.Error("filters with different sizes are equal")
+	}
+	if a.Equals(nil) {
+		t.Error("Equals(nil) = true")
+	}
+}
+
+func TestNewBloomFilterCheckedRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name          string
+		expectedItems int
+		rate          float64
+	}{
+		{"zero items", 0, 0.01},
+		{"negative items", -1, 0.01},
+		{"zero rate", 1000, 0},
+		{"rate of one", 1000, 1},
+		{"negative rate", 1000, -0.5},
+		{"rate above one", 1000, 1.5},
+		{"NaN rate", 1000, math.NaN()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if bf, err := NewBloomFilterChecked(tt.expectedItems, tt.rate); err == nil {
+				t.Errorf("NewBloomFilterChecked(%d, %v) = size %d, want error", tt.expectedItems, tt.rate, bf.size)
+			}
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewBloomFilter(%d, %v) did not panic", tt.expectedItems, tt.rate)
+				}
+			}()
+			NewBloomFilter(tt.expectedItems, tt.rate)
+		})
+	}
+
+	if _, err := NewBloomFilterChecked(1000, 0.01); err != nil {
+		t.Errorf("NewBloomFilterChecked(1000, 0.01): %v", err)
+	}
+}
+
+func TestOptimalParametersReferenceValues(t *testing.T) {
+	// m = ceil(-n ln p / (ln 2)^2), k = ceil(m/n ln 2)
+	tests := []struct {
+		expectedItems int
+		rate          float64
+		size          int
+		numHashes     int
+	}{
+		{1000, 0.01, 9586, 7},
+		{1000, 0.001, 14378, 10},
+		{1_000_000, 0.01, 9585059, 7},
+	}
+	for _, tt := range tests {
+		size, numHashes := OptimalParameters(tt.expectedItems, tt.rate)
+		if size != tt.size || numHashes != tt.numHashes {
+			t.Errorf("OptimalParameters(%d, %v) = (%d, %d), want (%d, %d)",
+				tt.expectedItems, tt.rate, size, numHashes, tt.size, tt.numHashes)
+		}
+	}
+
+	bf := NewBloomFilter(1000, 0.01)
+	if bf.size != 9586 || bf.numHashes != 7 {
+		t.Errorf("NewBloomFilter(1000, 0.01) has size=%d numHashes=%d, want 9586 and 7", bf.size, bf.numHashes)
+	}
+}
+
+func TestTestAllMatchesTest(t *testing.T) {
+	bf := NewBloomFilter(100, 0.01)
+	for _, key := range benchKeys(50) {
+		bf.Add(key)
+	}
+
+	items := append(benchKeys(100), "", "absent")
+	results := bf.TestAll(items)
+	if len(results) != len(items) {
+		t.Fatalf("TestAll returned %d results for %d items", len(results), len(items))
+	}
+	for i, item := range items {
+		if results[i] != bf.Test(item) {
+			t.Errorf("TestAll[%d] = %v, Test(%q) = %v", i, results[i], item, bf.Test(item))
+		}
+	}
+	if got := bf.TestAll(nil); len(got) != 0 {
+		t.Errorf("TestAll(nil) = %v, want empty", got)
+	}
+}
@@ -0,0 +1,89 @@
+package bloomfilter
+
+import "math"
+
+// CountingBloomFilter は削除に対応したBloom Filter
+// ビットの代わりにカウンタを持ち、Addでインクリメント、Removeでデクリメントする
+type CountingBloomFilter struct {
+	counters  []uint8 // カウンタ配列
+	size      int     // カウンタ配列のサイズ
+	numHashes int     // ハッシュ関数の数
+	numItems  int     // 追加されているアイテム数
+}
+
+// NewCountingBloomFilter は新しいCountingBloomFilterを作成
+// パラメータの意味と最適サイズの計算はNewBloomFilterと同じ
+func NewCountingBloomFilter(expectedItems int, falsePositiveRate float64) *CountingBloomFilter {
+	bf := NewBloomFilter(expectedItems, falsePositiveRate)
+
+	return &CountingBloomFilter{
+		counters:  make([]uint8, bf.size),
+		size:      bf.size,
+		numHashes: bf.numHashes,
+		numItems:  0,
+	}
+}
+
+// Add はアイテムを追加し、対応するカウンタをインクリメント
+// 最大値に達したカウンタは飽和したまま固定する
+func (cbf *CountingBloomFilter) Add(item string) {
+	for _, idx := range hashIndices([]byte(item), cbf.numHashes, cbf.size, 0) {
+		if cbf.counters[idx] < math.MaxUint8 {
+			cbf.counters[idx]++
+		}
+	}
+
+	cbf.numItems++
+}
+
+// Remove はアイテムを削除し、対応するカウンタをデクリメント
+// 追加されていないアイテム（いずれかのカウンタが0）の場合は何もせずfalseを返す
+// 飽和したカウンタは本来の値が分からないためデクリメントしない
+// （実際の回数より小さくなって他のアイテムの偽陰性を生むのを防ぐ）
+func (cbf *CountingBloomFilter) Remove(item string) bool {
+	indices := hashIndices([]byte(item), cbf.numHashes, cbf.size, 0)
+
+	// アンダーフローを防ぐため、先に全カウンタが非0であることを確認
+	for _, idx := range indices {
+		if cbf.counters[idx] == 0 {
+			return false // 確実に存在しない
+		}
+	}
+
+	for _, idx := range indices {
+		if cbf.counters[idx] < math.MaxUint8 {
+			cbf.counters[idx]--
+		}
+	}
+
+	if cbf.numItems > 0 {
+		cbf.numItems--
+	}
+	return true
+}
+
+// EstimateCardinality は現在含まれている重複のないアイテム数を推定
+// 非0のカウンタの割合からBloomFilter.EstimateCardinalityと同じ式で逆算する
+// 削除でカウンタが0に戻るため、通常のBloom Filterと異なり推定値も削除に応じて減る
+// （飽和したカウンタは0に戻らないため、その分だけ過大になり得る）
+func (cbf *CountingBloomFilter) EstimateCardinality() int {
+	nonZero := 0
+	for _, c := range cbf.counters {
+		if c != 0 {
+			nonZero++
+		}
+	}
+	return cardinalityFromFill(cbf.size, cbf.numHashes, nonZero)
+}
+
+// Test はアイテムが存在する可能性があるかテスト
+// 全てのカウンタが非0ならtrue
+func (cbf *CountingBloomFilter) Test(item string) bool {
+	for _, idx := range hashIndices([]byte(item), cbf.numHashes, cbf.size, 0) {
+		if cbf.counters[idx] == 0 {
+			return false // 確実に存在しない
+		}
+	}
+
+	return true // 存在する可能性がある
+}
@@ -0,0 +1,92 @@
+package bloomfilter
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestCountingFilterAddRemove(t *testing.T) {
+	cbf := NewCountingBloomFilter(1000, 0.01)
+	cbf.Add("apple")
+	cbf.Add("banana")
+
+	if !cbf.Remove("apple") {
+		t.Fatal("Remove(apple) = false, want true")
+	}
+	if cbf.Test("apple") {
+		t.Error("Test(apple) = true after Remove")
+	}
+	if !cbf.Test("banana") {
+		t.Error("Test(banana) = false after removing another item")
+	}
+}
+
+func TestCountingFilterRemoveNeverAddedDoesNotUnderflow(t *testing.T) {
+	cbf := NewCountingBloomFilter(1000, 0.01)
+	cbf.Add("apple")
+	before := slices.Clone(cbf.counters)
+
+	if cbf.Remove("never-added") {
+		t.Error("Remove of an item never added = true, want false")
+	}
+	if !slices.Equal(cbf.counters, before) {
+		t.Error("Remove of an item never added changed the counters")
+	}
+	if cbf.numItems != 1 {
+		t.Errorf("numItems = %d, want 1", cbf.numItems)
+	}
+}
+
+func TestCountingFilterSaturatedCountersStayPinned(t *testing.T) {
+	cbf := NewCountingBloomFilter(1000, 0.01)
+	const adds = math.MaxUint8 + 10
+	for range adds {
+		cbf.Add("hot")
+	}
+
+	// 飽和したカウンタは本来の回数が分からないため、同じ回数Removeしても0にならない
+	for range adds {
+		cbf.Remove("hot")
+	}
+	if !cbf.Test("hot") {
+		t.Error("saturated counters were decremented to zero")
+	}
+	for _, idx := range hashIndices([]byte("hot"), cbf.numHashes, cbf.size, 0) {
+		if cbf.counters[idx] != math.MaxUint8 {
+			t.Errorf("counter %d = %d, want it pinned at %d", idx, cbf.counters[idx], math.MaxUint8)
+		}
+	}
+}
+
+func TestCountingFilterEstimateCardinalityTracksRemovals(t *testing.T) {
+	cbf := NewCountingBloomFilter(2000, 0.01)
+	keys := benchKeys(1000)
+	for _, key := range keys {
+		cbf.Add(key)
+	}
+
+	// 推定値は追加・削除後の実際のアイテム数の±5%以内
+	within := func(got, want int) bool {
+		return math.Abs(float64(got-want)) <= 0.05*float64(want)
+	}
+	if got := cbf.EstimateCardinality(); !within(got, 1000) {
+		t.Errorf("after adding 1000 items: EstimateCardinality = %d", got)
+	}
+
+	for _, key := range keys[:600] {
+		if !cbf.Remove(key) {
+			t.Fatalf("Remove(%q) = false", key)
+		}
+	}
+	if got := cbf.EstimateCardinality(); !within(got, 400) {
+		t.Errorf("after removing 600 items: EstimateCardinality = %d, want about 400", got)
+	}
+
+	for _, key := range keys[600:] {
+		cbf.Remove(key)
+	}
+	if got := cbf.EstimateCardinality(); got != 0 {
+		t.Errorf("after removing every item: EstimateCardinality = %d, want 0", got)
+	}
+}
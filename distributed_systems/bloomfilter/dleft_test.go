@@ -0,0 +1,69 @@
+package bloomfilter
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestDLeftRemoveKeepsCollidingItems(t *testing.T) {
+	// バケットが1つしかないため、すべてのアイテムが同じ候補バケットに衝突する
+	df, err := NewDLeftCountingFilter(dLeftTargetLoad, 1)
+	if err != nil {
+		t.Fatalf("NewDLeftCountingFilter: %v", err)
+	}
+	items := []string{"apple", "banana", "cherry", "date"}
+	for _, item := range items {
+		if err := df.Add(item); err != nil {
+			t.Fatalf("Add(%q): %v", item, err)
+		}
+	}
+
+	// 途中のセルを削除しても残りのアイテムは見つかる
+	if !df.Remove("banana") {
+		t.Fatal("Remove(banana) = false, want true")
+	}
+	if df.Test("banana") {
+		t.Error("Test(banana) = true after Remove")
+	}
+	for _, item := range []string{"apple", "cherry", "date"} {
+		if !df.Test(item) {
+			t.Errorf("Test(%q) = false after removing a colliding item", item)
+		}
+	}
+	if df.Remove("banana") {
+		t.Error("second Remove(banana) = true, want false")
+	}
+}
+
+func TestDLeftDuplicateAddNeedsMatchingRemoves(t *testing.T) {
+	df, err := NewDLeftCountingFilter(100, 4)
+	if err != nil {
+		t.Fatalf("NewDLeftCountingFilter: %v", err)
+	}
+	df.Add("apple")
+	df.Add("apple")
+
+	df.Remove("apple")
+	if !df.Test("apple") {
+		t.Fatal("Test(apple) = false after removing one of two adds")
+	}
+	df.Remove("apple")
+	if df.Test("apple") {
+		t.Error("Test(apple) = true after removing both adds")
+	}
+}
+
+func TestDLeftFullBucketsRejectAdd(t *testing.T) {
+	df, err := NewDLeftCountingFilter(dLeftTargetLoad, 1)
+	if err != nil {
+		t.Fatalf("NewDLeftCountingFilter: %v", err)
+	}
+	for i := range dLeftCellsPerBucket {
+		if err := df.Add("item_" + strconv.Itoa(i)); err != nil {
+			t.Fatalf("Add #%d: %v", i, err)
+		}
+	}
+	if err := df.Add("overflow"); err == nil {
+		t.Error("Add into full buckets succeeded, want error")
+	}
+}
@@ -0,0 +1,175 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// バイナリ形式のバージョン
+// 2: ダブルハッシュ法への変更（バージョン1のビット配置とは互換性がない）
+// 3: シードを追加
+// 4: 基底ハッシュの種類を追加
+// 5: 容量を追加
+const binaryVersion = 5
+
+// binaryHeaderSize はバイナリ形式のヘッダ長
+// バージョン(1) + size(8) + numHashes(8) + numItems(8) + seed(8) + 基底ハッシュ(1) + capacity(8)
+const binaryHeaderSize = 1 + 8 + 8 + 8 + 8 + 1 + 8
+
+// maxDecodedHashes は読み込みで受け付けるハッシュ関数の数の上限
+// float64で表せる最小の偽陽性率でもOptimalParametersのハッシュ関数の数は約1075個のため、
+// これを超える値は破損したデータとみなす（Testが事実上終わらなくなるのを防ぐ）
+const maxDecodedHashes = 2048
+
+// streamChunkSize はストリームの読み書きでビット配列を区切る単位（バイト）
+const streamChunkSize = 4096
+
+// MarshalBinary はBloom Filterをバイナリ形式に変換（encoding.BinaryMarshaler）
+// 形式: バージョン, size, numHashes, numItems, seed（ビッグエンディアン）, 基底ハッシュ,
+// capacity（ビッグエンディアン）, 8ビットずつ詰めたビット配列
+func (bf *BloomFilter) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(binaryHeaderSize + (bf.size+7)/8)
+	if _, err := bf.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary はバイナリ形式からBloom Filterを復元（encoding.BinaryUnmarshaler）
+// インデックスはパラメータのみから決まるため、元のフィルタと同じTest結果になる
+func (bf *BloomFilter) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	if _, err := bf.ReadFrom(r); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("bloom filter data has %d trailing bytes", r.Len())
+	}
+	return nil
+}
+
+// WriteTo はBloom Filterをバイナリ形式でwに書き出す（io.WriterTo）
+// ビット配列全体をバイト列として確保せず、一定サイズずつ書き出す
+// 書き込んだバイト数を返す
+// 呼び出し側が指定したハッシュ関数は復元できないため、その場合はエラーを返す
+func (bf *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	if bf.hashFuncs != nil {
+		return 0, errors.New("bloom filter with custom hash functions cannot be serialized")
+	}
+
+	header := make([]byte, binaryHeaderSize)
+	header[0] = binaryVersion
+	binary.BigEndian.PutUint64(header[1:], uint64(bf.size))
+	binary.BigEndian.PutUint64(header[9:], uint64(bf.numHashes))
+	binary.BigEndian.PutUint64(header[17:], uint64(bf.numItems))
+	binary.BigEndian.PutUint64(header[25:], bf.seed)
+	header[33] = byte(bf.scheme)
+	binary.BigEndian.PutUint64(header[34:], uint64(bf.capacity))
+
+	n, err := w.Write(header)
+	written := int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	chunk := make([]byte, streamChunkSize)
+	totalBytes := (bf.size + 7) / 8
+	for start := 0; start < totalBytes; start += streamChunkSize {
+		packed := chunk[:min(streamChunkSize, totalBytes-start)]
+		for i := range packed {
+			// バイトjはワードj/8の (j%8)*8 ビット目からの8ビット
+			j := start + i
+			packed[i] = byte(bf.bitArray[j/8] >> (j % 8 * 8))
+		}
+
+		n, err := w.Write(packed)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// ReadFrom はrからバイナリ形式のBloom Filterを読み込む（io.ReaderFrom）
+// 途中で読み込みが途切れた場合はエラーを返し、Bloom Filterは変更しない
+// 読み込んだバイト数を返す
+func (bf *BloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	header := make([]byte, binaryHeaderSize)
+	n, err := io.ReadFull(r, header)
+	read := int64(n)
+	if err != nil {
+		return read, fmt.Errorf("reading bloom filter header: %w", err)
+	}
+
+	if header[0] != binaryVersion {
+		return read, fmt.Errorf("unsupported bloom filter version %d, expected %d", header[0], binaryVersion)
+	}
+	size := binary.BigEndian.Uint64(header[1:])
+	numHashes := binary.BigEndian.Uint64(header[9:])
+	numItems := binary.BigEndian.Uint64(header[17:])
+	seed := binary.BigEndian.Uint64(header[25:])
+	scheme := hashScheme(header[33])
+	capacity := binary.BigEndian.Uint64(header[34:])
+	if size == 0 || numHashes == 0 {
+		return read, errors.New("bloom filter size and num hashes must be positive")
+	}
+	// (size+7)/8 がintで溢れないこと
+	if size > math.MaxInt-7 {
+		return read, fmt.Errorf("bloom filter size %d is too large", size)
+	}
+	if numHashes > maxDecodedHashes {
+		return read, fmt.Errorf("bloom filter num hashes %d exceeds limit %d", numHashes, maxDecodedHashes)
+	}
+	if numItems > math.MaxInt || capacity > math.MaxInt {
+		return read, fmt.Errorf("bloom filter item counts out of range: num items %d, capacity %d", numItems, capacity)
+	}
+	if scheme != schemeSHA256 && scheme != schemeMurmur3 {
+		return read, fmt.Errorf("unknown bloom filter hash scheme %d", scheme)
+	}
+
+	loaded := &BloomFilter{
+		size:      int(size),
+		numHashes: int(numHashes),
+		numItems:  int(numItems),
+		seed:      seed,
+		scheme:    scheme,
+		capacity:  int(capacity),
+	}
+
+	// sizeが不正に大きい場合に備え、ビット配列は読み込めた分だけ伸ばす
+	chunk := make([]byte, streamChunkSize)
+	totalBytes := (loaded.size + 7) / 8
+	for start := 0; start < totalBytes; start += streamChunkSize {
+		packed := chunk[:min(streamChunkSize, totalBytes-start)]
+		n, err := io.ReadFull(r, packed)
+		read += int64(n)
+		if err != nil {
+			return read, fmt.Errorf("reading bloom filter bit array at byte %d of %d: %w", start+n, totalBytes, err)
+		}
+
+		// バイト列をuint64のワードに詰め直す
+		for i, b := range packed {
+			j := start + i
+			if j%8 == 0 {
+				loaded.bitArray = append(loaded.bitArray, 0)
+			}
+			loaded.bitArray[j/8] |= uint64(b) << (j % 8 * 8)
+		}
+	}
+
+	if len(loaded.bitArray) != numWords(loaded.size) {
+		return read, fmt.Errorf("bloom filter bit array has %d words, expected %d for size %d",
+			len(loaded.bitArray), numWords(loaded.size), loaded.size)
+	}
+
+	loaded.setBits = loaded.countSetBits()
+	*bf = *loaded
+	return read, nil
+}
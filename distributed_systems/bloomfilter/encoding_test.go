@@ -0,0 +1,60 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// headerOnly はビット配列を持たないヘッダだけのバイナリ形式を返す
+func headerOnly(size, numHashes uint64) []byte {
+	header := make([]byte, binaryHeaderSize)
+	header[0] = binaryVersion
+	binary.BigEndian.PutUint64(header[1:], size)
+	binary.BigEndian.PutUint64(header[9:], numHashes)
+	return header
+}
+
+func TestReadFromRejectsInconsistentHeader(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      uint64
+		numHashes uint64
+	}{
+		{"size overflows int", 1 << 63, 3},
+		{"size without bit data", 1 << 20, 3},
+		{"too many hashes", 64, maxDecodedHashes + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bf BloomFilter
+			if _, err := bf.ReadFrom(bytes.NewReader(headerOnly(tt.size, tt.numHashes))); err == nil {
+				t.Fatalf("ReadFrom accepted size=%d numHashes=%d without bit data", tt.size, tt.numHashes)
+			}
+		})
+	}
+}
+
+func TestWriteToReadFromRoundTrip(t *testing.T) {
+	bf := NewBloomFilter(1000, 0.01)
+	for _, item := range []string{"apple", "banana", "cherry"} {
+		bf.Add(item)
+	}
+
+	var buf bytes.Buffer
+	if _, err := bf.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	var loaded BloomFilter
+	if _, err := loaded.ReadFrom(&buf); err != nil {
+		t.Fatalf("ReadFrom: %v", err)
+	}
+	if !loaded.Equals(bf) {
+		t.Fatal("loaded filter differs from the original")
+	}
+	for _, item := range []string{"apple", "banana", "cherry"} {
+		if !loaded.Test(item) {
+			t.Errorf("Test(%q) = false after round trip", item)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package bloomfilter
+
+// managedGrowth は再構築時の容量の倍率
+const managedGrowth = 2
+
+// ManagedBloomFilter は推定偽陽性率が閾値を超えると自動で大きく作り直すBloom Filter
+// 作り直すには追加済みのアイテムを新しいフィルタへ再投入する必要があるため、2つの方式がある
+//   - 保持方式（NewManagedBloomFilter）: 追加したアイテムを全てメモリに保持して再投入する。
+//     アイテムそのものを保持するためメモリ使用量はフィルタ本体よりはるかに大きくなり、
+//     所属判定を省メモリにするというBloom Filterの利点の大半を失う
+//   - 再読み込み方式（NewManagedBloomFilterWithReload）: アイテムは保持せず、
+//     再構築時にコールバックで元データ（DBやファイルなど）から再投入してもらう
+type ManagedBloomFilter struct {
+	filter            *BloomFilter                      // 現在のフィルタ
+	expectedItems     int                               // 現在のフィルタの想定アイテム数
+	falsePositiveRate float64                           // 各フィルタの目標偽陽性率
+	threshold         float64                           // 再構築する推定偽陽性率の閾値
+	items             [][]byte                          // 保持方式で保持するアイテム
+	reload            func(add func(item string)) error // 再読み込み方式のコールバック
+	rebuilds          int                               // 再構築した回数
+}
+
+// NewManagedBloomFilter は追加したアイテムを保持する方式のManagedBloomFilterを作成
+// threshold: 推定偽陽性率がこの値を超えたら再構築する（通常はfalsePositiveRateより大きい値）
+func NewManagedBloomFilter(expectedItems int, falsePositiveRate, threshold float64) *ManagedBloomFilter {
+	return &ManagedBloomFilter{
+		filter:            NewBloomFilter(expectedItems, falsePositiveRate),
+		expectedItems:     expectedItems,
+		falsePositiveRate: falsePositiveRate,
+		threshold:         threshold,
+	}
+}
+
+// NewManagedBloomFilterWithReload は再構築時にreloadで再投入する方式のManagedBloomFilterを作成
+// reloadは渡されたaddを、これまでに追加された全てのアイテムについて呼び出すこと
+// 再構築のきっかけになったアイテムはreloadの後にもう一度追加される
+func NewManagedBloomFilterWithReload(expectedItems int, falsePositiveRate, threshold float64, reload func(add func(item string)) error) *ManagedBloomFilter {
+	mbf := NewManagedBloomFilter(expectedItems, falsePositiveRate, threshold)
+	mbf.reload = reload
+	return mbf
+}
+
+// Add はアイテムを追加し、必要なら再構築する
+// 再読み込み方式でreloadが失敗した場合はエラーを返し、元のフィルタをそのまま使い続ける
+func (mbf *ManagedBloomFilter) Add(item string) error {
+	mbf.filter.Add(item)
+	if mbf.reload == nil {
+		mbf.items = append(mbf.items, []byte(item))
+	}
+
+	if mbf.filter.EstimateFalsePositiveRate() <= mbf.threshold {
+		return nil
+	}
+	return mbf.rebuild(item)
+}
+
+// rebuild は容量を増やした新しいフィルタを作り、アイテムを再投入する
+func (mbf *ManagedBloomFilter) rebuild(trigger string) error {
+	expectedItems := managedGrowth * max(mbf.expectedItems, mbf.filter.numItems)
+	filter := NewBloomFilter(expectedItems, mbf.falsePositiveRate)
+
+	if mbf.reload != nil {
+		if err := mbf.reload(filter.Add); err != nil {
+			return err
+		}
+		filter.Add(trigger)
+	} else {
+		for _, item := range mbf.items {
+			filter.AddBytes(item)
+		}
+	}
+
+	mbf.filter = filter
+	mbf.expectedItems = expectedItems
+	mbf.rebuilds++
+	return nil
+}
+
+// Test はアイテムが存在する可能性があるかテスト
+func (mbf *ManagedBloomFilter) Test(item string) bool {
+	return mbf.filter.Test(item)
+}
+
+// RebuildCount は再構築した回数を返す
+func (mbf *ManagedBloomFilter) RebuildCount() int {
+	return mbf.rebuilds
+}
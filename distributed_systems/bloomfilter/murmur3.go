@@ -0,0 +1,88 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// MurmurHash3 x64 128ビット版の定数
+const (
+	murmurC1 = 0x87c37b91114253d5
+	murmurC2 = 0x4cf5ad432745937f
+)
+
+// murmur3Sum128 はMurmurHash3 (x64, 128ビット) でデータをハッシュし、2つの64ビット値を返す
+// 外部パッケージに依存しないよう、Austin Applebyのリファレンス実装を移植したもの
+func murmur3Sum128(data []byte, seed uint32) (uint64, uint64) {
+	h1, h2 := uint64(seed), uint64(seed)
+
+	// 16バイトずつブロックを処理
+	nblocks := len(data) / 16
+	for i := 0; i < nblocks; i++ {
+		k1 := binary.LittleEndian.Uint64(data[i*16:])
+		k2 := binary.LittleEndian.Uint64(data[i*16+8:])
+
+		k1 *= murmurC1
+		k1 = bits.RotateLeft64(k1, 31)
+		k1 *= murmurC2
+		h1 ^= k1
+
+		h1 = bits.RotateLeft64(h1, 27)
+		h1 += h2
+		h1 = h1*5 + 0x52dce729
+
+		k2 *= murmurC2
+		k2 = bits.RotateLeft64(k2, 33)
+		k2 *= murmurC1
+		h2 ^= k2
+
+		h2 = bits.RotateLeft64(h2, 31)
+		h2 += h1
+		h2 = h2*5 + 0x38495ab5
+	}
+
+	// 残りのバイトを処理
+	tail := data[nblocks*16:]
+	var k1, k2 uint64
+	for i := len(tail) - 1; i >= 8; i-- {
+		k2 ^= uint64(tail[i]) << ((i - 8) * 8)
+	}
+	if len(tail) > 8 {
+		k2 *= murmurC2
+		k2 = bits.RotateLeft64(k2, 33)
+		k2 *= murmurC1
+		h2 ^= k2
+	}
+	for i := min(len(tail), 8) - 1; i >= 0; i-- {
+		k1 ^= uint64(tail[i]) << (i * 8)
+	}
+	if len(tail) > 0 {
+		k1 *= murmurC1
+		k1 = bits.RotateLeft64(k1, 31)
+		k1 *= murmurC2
+		h1 ^= k1
+	}
+
+	// 最終混合
+	h1 ^= uint64(len(data))
+	h2 ^= uint64(len(data))
+	h1 += h2
+	h2 += h1
+	h1 = fmix64(h1)
+	h2 = fmix64(h2)
+	h1 += h2
+	h2 += h1
+
+	return h1, h2
+}
+
+// fmix64 はMurmurHash3の64ビット最終混合関数
+// 入力の各ビットを出力全体に拡散させる
+func fmix64(k uint64) uint64 {
+	k ^= k >> 33
+	k *= 0xff51afd7ed558ccd
+	k ^= k >> 33
+	k *= 0xc4ceb9fe1a85ec53
+	k ^= k >> 33
+	return k
+}
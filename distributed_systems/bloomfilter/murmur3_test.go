@@ -0,0 +1,58 @@
+package bloomfilter
+
+import "testing"
+
+func TestMurmur3Sum128ReferenceVectors(t *testing.T) {
+	// MurmurHash3_x64_128（シード0）の参照実装の出力
+	tests := []struct {
+		data   string
+		h1, h2 uint64
+	}{
+		{"", 0, 0},
+		{"hello", 0xcbd8a7b341bd9b02, 0x5b1e906a48ae1d19},
+		{"The quick brown fox jumps over the lazy dog", 0xe34bbc7bbc071b6c, 0x7a433ca9c49a9347},
+	}
+	for _, tt := range tests {
+		if h1, h2 := murmur3Sum128([]byte(tt.data), 0); h1 != tt.h1 || h2 != tt.h2 {
+			t.Errorf("murmur3Sum128(%q) = %016x %016x, want %016x %016x", tt.data, h1, h2, tt.h1, tt.h2)
+		}
+	}
+}
+
+func TestMurmurFilterMembership(t *testing.T) {
+	keys := benchKeys(10_000)
+	bf := NewBloomFilterMurmur(len(keys), 0.01)
+	for _, key := range keys {
+		bf.Add(key)
+	}
+	for _, key := range keys {
+		if !bf.Test(key) {
+			t.Fatalf("Test(%q) = false after Add", key)
+		}
+	}
+	if rate := measuredFalsePositiveRate(bf, 100_000); rate > 0.015 {
+		t.Errorf("measured false positive rate %.4f, want about 0.01", rate)
+	}
+}
+
+// BenchmarkMurmurVsSHA256 はSHA-256とMurmurHash3を基底ハッシュにした場合のTestを比較する
+func BenchmarkMurmurVsSHA256(b *testing.B) {
+	keys := benchKeys(10_000)
+	for _, bc := range []struct {
+		name string
+		bf   *BloomFilter
+	}{
+		{"sha256", NewBloomFilter(len(keys), 0.01)},
+		{"murmur3", NewBloomFilterMurmur(len(keys), 0.01)},
+	} {
+		for _, key := range keys {
+			bc.bf.Add(key)
+		}
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bc.bf.Test(keys[i%len(keys)])
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package bloomfilter
+
+// PartitionedBloomFilter はハッシュ関数ごとにビット配列を分割したBloom Filter
+// ビット配列をnumHashes個の等しいパーティションに分け、i番目のハッシュは
+// i番目のパーティションだけを指す。ハッシュ同士が同じビットを指すことがないため
+// ビットの埋まり方が均一になり、偽陽性率がより予測しやすくなる
+type PartitionedBloomFilter struct {
+	filter        *BloomFilter // ビット配列と統計情報を保持するフィルタ
+	partitionSize int          // 1パーティションのビット数
+}
+
+// NewPartitionedBloomFilter は新しいPartitionedBloomFilterを作成
+// パラメータの意味と最適サイズの計算はNewBloomFilterと同じ
+// 全体のサイズはパーティション数で割り切れるよう切り上げる
+func NewPartitionedBloomFilter(expectedItems int, falsePositiveRate float64) *PartitionedBloomFilter {
+	bf := NewBloomFilter(expectedItems, falsePositiveRate)
+
+	partitionSize := (bf.size + bf.numHashes - 1) / bf.numHashes
+	bf.size = partitionSize * bf.numHashes
+	bf.bitArray = make([]uint64, numWords(bf.size))
+
+	return &PartitionedBloomFilter{
+		filter:        bf,
+		partitionSize: partitionSize,
+	}
+}
+
+// getHashes はデータに対して各パーティション内のインデックスを計算
+func (pbf *PartitionedBloomFilter) getHashes(data []byte) []int {
+	hashes := hashIndices(data, pbf.filter.numHashes, pbf.partitionSize, 0)
+	for i := range hashes {
+		hashes[i] += i * pbf.partitionSize
+	}
+	return hashes
+}
+
+// Add はアイテムを追加
+func (pbf *PartitionedBloomFilter) Add(item string) {
+	for _, hash := range pbf.getHashes([]byte(item)) {
+		pbf.filter.setBit(hash)
+	}
+
+	pbf.filter.numItems++
+}
+
+// Test はアイテムが存在する可能性があるかテスト
+func (pbf *PartitionedBloomFilter) Test(item string) bool {
+	for _, hash := range pbf.getHashes([]byte(item)) {
+		if !pbf.filter.getBit(hash) {
+			return false // 確実に存在しない
+		}
+	}
+
+	return true // 存在する可能性がある
+}
+
+// Stats は統計情報を返す（BloomFilter.Statsと同じ項目にpartition_sizeを加えたもの）
+func (pbf *PartitionedBloomFilter) Stats() map[string]interface{} {
+	stats := pbf.filter.Stats()
+	stats["partition_size"] = pbf.partitionSize
+	return stats
+}
@@ -0,0 +1,54 @@
+package bloomfilter
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestPartitionedVsPlainAtHighLoad(t *testing.T) {
+	// 想定の3倍のアイテムを入れて偽陽性率が大きくなる状態で比較する
+	keys := benchKeys(3000)
+	plain := NewBloomFilter(1000, 0.01)
+	partitioned := NewPartitionedBloomFilter(1000, 0.01)
+	for _, key := range keys {
+		plain.Add(key)
+		partitioned.Add(key)
+	}
+
+	const samples = 200_000
+	positives := 0
+	for i := range samples {
+		if partitioned.Test("absent_" + strconv.Itoa(i)) {
+			positives++
+		}
+	}
+	partitionedRate := float64(positives) / samples
+	plainRate := measuredFalsePositiveRate(plain, samples)
+
+	k, n, m := float64(plain.numHashes), float64(len(keys)), float64(plain.size)
+	theory := math.Pow(1-math.Exp(-k*n/m), k)
+	for name, rate := range map[string]float64{"plain": plainRate, "partitioned": partitionedRate} {
+		if math.Abs(rate-theory) > 0.05*theory {
+			t.Errorf("%s measured rate %.4f, want within 5%% of theoretical %.4f", name, rate, theory)
+		}
+	}
+	if partitionedRate > 1.05*plainRate {
+		t.Errorf("partitioned rate %.4f is worse than plain %.4f", partitionedRate, plainRate)
+	}
+
+	// 各パーティションには各アイテムがちょうど1ビットずつ立てるため、埋まり方が揃う
+	size := partitioned.partitionSize
+	want := 1 - math.Exp(-n/float64(size))
+	for p := range partitioned.filter.numHashes {
+		set := 0
+		for i := p * size; i < (p+1)*size; i++ {
+			if partitioned.filter.getBit(i) {
+				set++
+			}
+		}
+		if fill := float64(set) / float64(size); math.Abs(fill-want) > 0.03 {
+			t.Errorf("partition %d fill %.3f, want about %.3f", p, fill, want)
+		}
+	}
+}
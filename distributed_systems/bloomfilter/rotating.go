@@ -0,0 +1,65 @@
+package bloomfilter
+
+import "time"
+
+// RotatingBloomFilter は時間窓ごとにBloom Filterを切り替えるフィルタ
+// 現在の窓(active)と直前の窓(previous)の2つを保持し、
+// Testは両方を確認するため、切り替え直後も直前の窓のアイテムは失われない
+type RotatingBloomFilter struct {
+	active            *BloomFilter  // 現在追加先となっているフィルタ
+	previous          *BloomFilter  // 直前の窓のフィルタ（初回はnil）
+	expectedItems     int           // 各窓で予想されるアイテム数
+	falsePositiveRate float64       // 各窓の目標偽陽性率
+	maxLoadFactor     float64       // 切り替えるロードファクタの閾値（0以下で無効）
+	maxAge            time.Duration // 切り替える窓の経過時間（0以下で無効）
+	windowStart       time.Time     // 現在の窓の開始時刻
+}
+
+// NewRotatingBloomFilter は新しいRotatingBloomFilterを作成
+// maxLoadFactor: activeのロードファクタがこの値を超えたら切り替える
+// maxAge: 現在の窓がこの時間を超えたら切り替える
+func NewRotatingBloomFilter(expectedItems int, falsePositiveRate, maxLoadFactor float64, maxAge time.Duration) *RotatingBloomFilter {
+	return &RotatingBloomFilter{
+		active:            NewBloomFilter(expectedItems, falsePositiveRate),
+		expectedItems:     expectedItems,
+		falsePositiveRate: falsePositiveRate,
+		maxLoadFactor:     maxLoadFactor,
+		maxAge:            maxAge,
+		windowStart:       time.Now(),
+	}
+}
+
+// Add は現在の窓のフィルタにアイテムを追加
+func (rf *RotatingBloomFilter) Add(item string) {
+	rf.active.Add(item)
+}
+
+// Test は現在の窓と直前の窓のどちらかにアイテムが存在する可能性があるかテスト
+func (rf *RotatingBloomFilter) Test(item string) bool {
+	if rf.active.Test(item) {
+		return true
+	}
+	return rf.previous != nil && rf.previous.Test(item)
+}
+
+// Rotate は窓を切り替える
+// 現在のフィルタを直前の窓に回し、新しい空のフィルタを追加先にする
+func (rf *RotatingBloomFilter) Rotate() {
+	rf.previous = rf.active
+	rf.active = NewBloomFilter(rf.expectedItems, rf.falsePositiveRate)
+	rf.windowStart = time.Now()
+}
+
+// ShouldRotate は切り替えるべきかを判定
+// activeのロードファクタがmaxLoadFactorを超えた場合、または
+// 現在の窓の経過時間がmaxAgeを超えた場合のどちらか早い方でtrueを返す
+func (rf *RotatingBloomFilter) ShouldRotate() bool {
+	if rf.maxLoadFactor > 0 {
+		loadFactor := float64(rf.active.setBits) / float64(rf.active.size)
+		if loadFactor > rf.maxLoadFactor {
+			return true
+		}
+	}
+
+	return rf.maxAge > 0 && time.Since(rf.windowStart) > rf.maxAge
+}
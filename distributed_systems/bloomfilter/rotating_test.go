@@ -0,0 +1,51 @@
+package bloomfilter
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestShouldRotateByFill(t *testing.T) {
+	rf := NewRotatingBloomFilter(100, 0.01, 0.3, 0)
+	if rf.ShouldRotate() {
+		t.Fatal("ShouldRotate() = true on an empty filter")
+	}
+
+	for i := 0; !rf.ShouldRotate(); i++ {
+		if i > 1000 {
+			t.Fatal("ShouldRotate() never tripped while filling the active filter")
+		}
+		rf.Add("item_" + strconv.Itoa(i))
+	}
+	if load := float64(rf.active.setBits) / float64(rf.active.size); load <= 0.3 {
+		t.Errorf("tripped at load factor %.3f, want > 0.3", load)
+	}
+
+	rf.Rotate()
+	if rf.ShouldRotate() {
+		t.Error("ShouldRotate() = true right after Rotate")
+	}
+	if !rf.Test("item_0") {
+		t.Error("item from the previous window was lost after Rotate")
+	}
+}
+
+func TestShouldRotateByAge(t *testing.T) {
+	rf := NewRotatingBloomFilter(100, 0.01, 0, time.Minute)
+	rf.Add("item")
+	if rf.ShouldRotate() {
+		t.Fatal("ShouldRotate() = true on a fresh window")
+	}
+
+	// 待つ代わりに窓の開始時刻を過去にずらす
+	rf.windowStart = time.Now().Add(-2 * time.Minute)
+	if !rf.ShouldRotate() {
+		t.Error("ShouldRotate() = false after the window exceeded maxAge")
+	}
+
+	rf.Rotate()
+	if rf.ShouldRotate() {
+		t.Error("ShouldRotate() = true right after Rotate")
+	}
+}
@@ -0,0 +1,84 @@
+package bloomfilter
+
+import "math"
+
+const (
+	scalableGrowth     = 2   // ステージごとの容量の倍率
+	scalableTightening = 0.9 // ステージごとの偽陽性率の縮小率
+)
+
+// ScalableBloomFilter はアイテム数に合わせて自動的に拡張するBloom Filter
+// 事前にアイテム数が分からない場合に使う。アクティブなステージの偽陽性率が
+// 目標を超えると、より大きく偽陽性率を厳しくした新しいステージを追加する
+// ステージiの偽陽性率を p0 * r^i とすることで、全体の偽陽性率は目標以下に収まる
+type ScalableBloomFilter struct {
+	stages       []*BloomFilter // ステージのリスト（最後がアクティブ）
+	stageRates   []float64      // 各ステージの目標偽陽性率
+	initialItems int            // 最初のステージで予想されるアイテム数
+	targetRate   float64        // 全体の目標偽陽性率
+}
+
+// NewScalableBloomFilter は新しいScalableBloomFilterを作成
+// initialItems: 最初のステージで予想されるアイテム数
+// targetRate: 全体の目標偽陽性率 (0.0 < rate < 1.0)
+func NewScalableBloomFilter(initialItems int, targetRate float64) *ScalableBloomFilter {
+	sbf := &ScalableBloomFilter{
+		initialItems: initialItems,
+		targetRate:   targetRate,
+	}
+	sbf.addStage()
+	return sbf
+}
+
+// addStage は新しいステージを追加
+func (sbf *ScalableBloomFilter) addStage() {
+	i := len(sbf.stages)
+
+	// 無限等比級数の和が目標以下になるよう、最初のステージの偽陽性率を p0 = P * (1 - r) とする
+	rate := sbf.targetRate * (1 - scalableTightening) * math.Pow(scalableTightening, float64(i))
+	items := sbf.initialItems * int(math.Pow(scalableGrowth, float64(i)))
+
+	sbf.stages = append(sbf.stages, NewBloomFilter(items, rate))
+	sbf.stageRates = append(sbf.stageRates, rate)
+}
+
+// Add はアイテムを追加
+// アクティブなステージの偽陽性率が目標を超えていれば、先に新しいステージを追加する
+func (sbf *ScalableBloomFilter) Add(item string) {
+	last := len(sbf.stages) - 1
+	if sbf.stages[last].EstimateFalsePositiveRate() > sbf.stageRates[last] {
+		sbf.addStage()
+		last++
+	}
+
+	sbf.stages[last].Add(item)
+}
+
+// Test はいずれかのステージにアイテムが存在する可能性があるかテスト
+func (sbf *ScalableBloomFilter) Test(item string) bool {
+	for _, stage := range sbf.stages {
+		if stage.Test(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// TotalSize は全ステージのビット配列サイズの合計を返す
+func (sbf *ScalableBloomFilter) TotalSize() int {
+	total := 0
+	for _, stage := range sbf.stages {
+		total += stage.size
+	}
+	return total
+}
+
+// EstimateFalsePositiveRate は全体の偽陽性率を推定
+// いずれかのステージで偽陽性になる確率: 1 - Π(1 - p_i)
+func (sbf *ScalableBloomFilter) EstimateFalsePositiveRate() float64 {
+	notFalsePositive := 1.0
+	for _, stage := range sbf.stages {
+		notFalsePositive *= 1 - stage.EstimateFalsePositiveRate()
+	}
+	return 1 - notFalsePositive
+}
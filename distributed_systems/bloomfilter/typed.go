@@ -0,0 +1,44 @@
+package bloomfilter
+
+import "encoding/binary"
+
+// TypedBloomFilter は任意の型の値を扱うBloom Filter
+// keyFuncで値を正規化したバイト列に変換してから内部のBloomFilterに渡す
+// 文字列を扱うBloomFilterは、keyFuncが[]byte(s)である場合の特殊化にあたる
+type TypedBloomFilter[T any] struct {
+	filter  *BloomFilter   // ビット配列と統計情報を保持するフィルタ
+	keyFunc func(T) []byte // 値をキーのバイト列に変換する関数
+}
+
+// NewTypedBloomFilter は新しいTypedBloomFilterを作成
+// パラメータの意味と最適サイズの計算はNewBloomFilterと同じ
+// keyFuncは同じ値に対して常に同じバイト列を返す必要がある
+func NewTypedBloomFilter[T any](expectedItems int, falsePositiveRate float64, keyFunc func(T) []byte) *TypedBloomFilter[T] {
+	return &TypedBloomFilter[T]{
+		filter:  NewBloomFilter(expectedItems, falsePositiveRate),
+		keyFunc: keyFunc,
+	}
+}
+
+// Add は値を追加
+func (tbf *TypedBloomFilter[T]) Add(v T) {
+	tbf.filter.AddBytes(tbf.keyFunc(v))
+}
+
+// Test は値が存在する可能性があるかテスト
+func (tbf *TypedBloomFilter[T]) Test(v T) bool {
+	return tbf.filter.TestBytes(tbf.keyFunc(v))
+}
+
+// Filter は内部のBloomFilterを返す（統計情報の取得やシリアライズに使う）
+func (tbf *TypedBloomFilter[T]) Filter() *BloomFilter {
+	return tbf.filter
+}
+
+// AppendKeyField は複合キーの1フィールドを長さ付きでbufに追加する
+// 長さを前置することで ("ab", "c") と ("a", "bc") のように
+// 単純な連結では区別できないフィールドの組を別のキーにする
+func AppendKeyField(buf []byte, field []byte) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(field)))
+	return append(buf, field...)
+}
@@ -0,0 +1,668 @@
+// Package consistenthash はコンシステントハッシュリングと、Rendezvous HashingやJump Consistent Hashを提供する
+package consistenthash
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// ConsistentHash はコンシステントハッシュリングを表す構造体
+// 全てのメソッドは並行に呼び出してよい（Add/Removeは書き込みロック、それ以外は読み込みロックを取る）
+type ConsistentHash struct {
+	mu         sync.RWMutex        // 以下のフィールドを保護する
+	replicas   int                 // 各ノードの仮想ノード数（重み1あたり）
+	keys       []uint32            // ソートされたハッシュ値のリスト
+	hashMap    map[uint32]string   // ハッシュ値からノード名へのマップ
+	nodeHashes map[string][]uint32 // 各物理ノードの仮想ノードのハッシュ値（レプリカ番号順）
+	meta       map[string]any      // AddWithMetaで登録したノードのメタデータ
+	gen        uint64              // Add/Removeのたびに増える世代番号
+	hasher     func([]byte) uint64 // キーと仮想ノード名のハッシュ関数
+}
+
+// New は新しいConsistentHashインスタンスを作成
+// ハッシュ関数にはSHA-1のダイジェストの先頭4バイトを使う
+func New(replicas int) *ConsistentHash {
+	return NewWithHasher(replicas, sha1Hasher)
+}
+
+// NewWithHasher はハッシュ関数を指定してConsistentHashを作成
+// 他のサービスと同じxxhashやFNVなどでリング上の位置を揃えるために使う
+// リングは32ビットの空間なので、hasherの戻り値は下位32ビットだけを使う
+func NewWithHasher(replicas int, hasher func([]byte) uint64) *ConsistentHash {
+	return &ConsistentHash{
+		replicas:   replicas,
+		hashMap:    make(map[uint32]string),
+		nodeHashes: make(map[string][]uint32),
+		meta:       make(map[string]any),
+		hasher:     hasher,
+	}
+}
+
+// NewChecked はreplicasを検証してConsistentHashを作成
+// replicasが0以下だと仮想ノードが作られず、Getが常に空文字列を返すリングになるためエラーを返す
+func NewChecked(replicas int) (*ConsistentHash, error) {
+	if replicas <= 0 {
+		return nil, fmt.Errorf("replicas must be positive, got %d", replicas)
+	}
+	return New(replicas), nil
+}
+
+// Replicas は重み1あたりの仮想ノード数を返す
+func (ch *ConsistentHash) Replicas() int {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return ch.replicas
+}
+
+// sha1Hasher はSHA-1のダイジェストの先頭4バイトをビッグエンディアンで読んだ値を返す（デフォルト）
+func sha1Hasher(data []byte) uint64 {
+	sum := sha1.Sum(data)
+	return uint64(binary.BigEndian.Uint32(sum[:4]))
+}
+
+// hash は文字列をリング上の位置（符号なし32ビット）に変換
+func (ch *ConsistentHash) hash(key string) uint32 {
+	return uint32(ch.hasher([]byte(key)))
+}
+
+// Add はハッシュリングにノードを追加
+// 複数のノードはAddBatchと同様にまとめて追加する
+func (ch *ConsistentHash) Add(nodes ...string) {
+	ch.AddBatch(nodes...)
+}
+
+// AddBatch は複数のノードをまとめてハッシュリングに追加
+// 全ての仮想ノードを追加してから新しい位置だけをソートし、既存の位置と1回でマージするため、
+// リングの大きさn、追加する仮想ノード数kに対してO(n + k log k)で済む
+// 既に登録されているノードは作り直し、同じノードを複数回指定した場合は1回だけ追加する
+func (ch *ConsistentHash) AddBatch(nodes ...string) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.addNodes(nodes, ch.replicas)
+	ch.gen++
+}
+
+// AddWeighted は容量に応じた重みを付けてノードを追加
+// replicas * weight 個の仮想ノードを作成するため、重み2のノードは重み1のノードの約2倍のキーを受け持つ
+// 既に登録されているノードを指定した場合は、新しい重みで作り直す
+// weightが1未満の場合は何もしない
+func (ch *ConsistentHash) AddWeighted(node string, weight int) {
+	if weight < 1 {
+		return
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.addNodes([]string{node}, ch.replicas*weight)
+	ch.gen++
+}
+
+// AddWithMeta はメタデータ（アドレスやデータセンターなど）を付けてノードを追加
+// メタデータはGetMetaでキーの担当ノードと一緒に取得でき、Removeで削除される
+// 既に登録されているノードを指定した場合は、仮想ノードを作り直してメタデータを置き換える
+func (ch *ConsistentHash) AddWithMeta(node string, meta any) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.addNodes([]string{node}, ch.replicas)
+	ch.meta[node] = meta
+	ch.gen++
+}
+
+// addNodes は各ノードにcount個の仮想ノードを作成し、keysをソート済みに保つ
+// 登録済みのノードは、keysがソート済みのうちに二分探索で削除してから作り直す
+func (ch *ConsistentHash) addNodes(nodes []string, count int) {
+	for _, node := range nodes {
+		if _, ok := ch.nodeHashes[node]; ok {
+			ch.removeNode(node)
+		}
+	}
+
+	sorted := len(ch.keys)
+	for _, node := range nodes {
+		if _, ok := ch.nodeHashes[node]; ok {
+			continue // 同じ呼び出しの中で追加済み
+		}
+		ch.addNode(node, count)
+	}
+	ch.mergeKeys(sorted)
+}
+
+// addNode は未登録のノードにcount個の仮想ノードを作成する（keysのソートは呼び出し側で行う）
+// 仮想ノードのハッシュ値が既存の位置と衝突した場合は、空いている位置が見つかるまで
+// 1つずつ先の位置を試す（線形探査）。決まった位置はnodeHashesに記録されるため、
+// Removeは衝突の有無に関わらず追加した位置を正しく削除できる
+// countが0以下の場合（replicasが0以下のリングなど）は仮想ノードを作らない
+func (ch *ConsistentHash) addNode(node string, count int) {
+	count = max(count, 0)
+	hashes := make([]uint32, 0, count)
+	for i := 0; i < count; i++ {
+		// 仮想ノード名を作成（ノード名 + レプリカ番号）
+		virtualNode := node + "#" + strconv.Itoa(i)
+		hash := ch.hash(virtualNode)
+		for {
+			if _, taken := ch.hashMap[hash]; !taken {
+				break
+			}
+			hash++ // uint32なので末尾を超えると先頭に戻る
+		}
+		ch.keys = append(ch.keys, hash)
+		ch.hashMap[hash] = node
+		hashes = append(hashes, hash)
+	}
+	ch.nodeHashes[node] = hashes
+}
+
+// clone はロックを取らずにリングの複製を作る（呼び出し側がロックを保持すること）
+// 複製への変更は元のリングに影響しない
+func (ch *ConsistentHash) clone() *ConsistentHash {
+	c := NewWithHasher(ch.replicas, ch.hasher)
+	c.keys = append([]uint32{}, ch.keys...)
+	for hash, node := range ch.hashMap {
+		c.hashMap[hash] = node
+	}
+	for node, hashes := range ch.nodeHashes {
+		c.nodeHashes[node] = append([]uint32{}, hashes...)
+	}
+	for node, m := range ch.meta {
+		c.meta[node] = m
+	}
+	c.gen = ch.gen
+	return c
+}
+
+// sortKeys はハッシュ値でkeysをソート
+func (ch *ConsistentHash) sortKeys() {
+	sort.Slice(ch.keys, func(i, j int) bool { return ch.keys[i] < ch.keys[j] })
+}
+
+// mergeKeys はソート済みのkeys[:sorted]に、末尾に追加されたkeys[sorted:]をソートしてマージする
+// 位置は線形探査で重複しないため、単純なマージでソート済みのkeysが得られる
+func (ch *ConsistentHash) mergeKeys(sorted int) {
+	added := ch.keys[sorted:]
+	if len(added) == 0 {
+		return
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i] < added[j] })
+
+	old := ch.keys[:sorted]
+	merged := make([]uint32, 0, len(ch.keys))
+	i, j := 0, 0
+	for i < len(old) && j < len(added) {
+		if old[i] < added[j] {
+			merged = append(merged, old[i])
+			i++
+		} else {
+			merged = append(merged, added[j])
+			j++
+		}
+	}
+	merged = append(merged, old[i:]...)
+	merged = append(merged, added[j:]...)
+	ch.keys = merged
+}
+
+// Remove はハッシュリングからノードを削除
+// 追加時に記録したハッシュ値だけを削除するため、重み付きで追加したノードも過不足なく削除される
+// 登録されていないノードを指定した場合は何もせずfalseを返す
+func (ch *ConsistentHash) Remove(node string) bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if _, ok := ch.nodeHashes[node]; !ok {
+		return false
+	}
+	ch.removeNode(node)
+	delete(ch.meta, node)
+	ch.gen++
+	return true
+}
+
+// RemoveChecked はハッシュリングからノードを削除し、登録されていないノードの場合はエラーを返す
+// クラスタ管理のコードでノード名の誤りを見逃さないために使う（Removeは何もせずfalseを返すだけ）
+func (ch *ConsistentHash) RemoveChecked(node string) error {
+	if !ch.Remove(node) {
+		return fmt.Errorf("node %q is not in the ring", node)
+	}
+	return nil
+}
+
+// removeNode はノードの仮想ノードを記録されたハッシュ値に従って削除
+// 仮想ノードごとに二分探索で位置を求めるため、仮想ノード数k、リングの大きさnに対してO(k log n)の探索で済む
+func (ch *ConsistentHash) removeNode(node string) {
+	for _, hash := range ch.nodeHashes[node] {
+		delete(ch.hashMap, hash)
+
+		// keysスライスから削除
+		idx := ch.search(hash)
+		if idx < len(ch.keys) && ch.keys[idx] == hash {
+			ch.keys = append(ch.keys[:idx], ch.keys[idx+1:]...)
+		}
+	}
+	delete(ch.nodeHashes, node)
+}
+
+// search はソートされたkeysスライス内でハッシュ値の挿入位置を検索
+func (ch *ConsistentHash) search(hash uint32) int {
+	return sort.Search(len(ch.keys), func(i int) bool {
+		return ch.keys[i] >= hash
+	})
+}
+
+// Get は指定されたキーに対応するノードを取得
+func (ch *ConsistentHash) Get(key string) string {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return ch.get(key)
+}
+
+// get はロックを取らずにGetを行う（呼び出し側がロックを保持すること）
+func (ch *ConsistentHash) get(key string) string {
+	node, _, _ := ch.getWithPosition(key)
+	return node
+}
+
+// GetWithPosition はキーに対応するノードと、キーが割り当てられた仮想ノードのリング上の位置を取得
+// 2つのキーが同じノードに集まる理由を調べるなど、ルーティングのデバッグに使う
+// foundはリングにノードがあったかを表し、falseの場合nodeは空文字列、positionは0になる
+func (ch *ConsistentHash) GetWithPosition(key string) (node string, position uint32, found bool) {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return ch.getWithPosition(key)
+}
+
+// getWithPosition はロックを取らずにGetWithPositionを行う（呼び出し側がロックを保持すること）
+func (ch *ConsistentHash) getWithPosition(key string) (node string, position uint32, found bool) {
+	if len(ch.keys) == 0 {
+		return "", 0, false
+	}
+
+	hash := ch.hash(key)
+
+	// ハッシュ値以上の最初のノードを検索
+	idx := ch.search(hash)
+
+	// リングの最後を超えた場合は最初のノードを返す
+	if idx == len(ch.keys) {
+		idx = 0
+	}
+
+	position = ch.keys[idx]
+	return ch.hashMap[position], position, true
+}
+
+// GetMeta はキーに対応するノードと、そのノードのメタデータを取得
+// メタデータなしで追加されたノードの場合metaはnil、ノードがない場合は空文字列とnilを返す
+func (ch *ConsistentHash) GetMeta(key string) (node string, meta any) {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	node = ch.get(key)
+	return node, ch.meta[node]
+}
+
+// NodesInRange はstartKeyとendKeyのハッシュ値で表されるリング上の区間[start, end)のいずれかの位置を
+// 担当する物理ノードを、startから時計回りに最初に現れる順で重複なく返す
+// 範囲で分割されたデータを走査する際に、問い合わせる必要のあるノードを求めるのに使う
+// startのハッシュ値がendより大きい場合は、リングの末尾を回り込む区間として扱う
+// 2つのハッシュ値が等しい場合は空の区間とみなし、空のスライスを返す
+func (ch *ConsistentHash) NodesInRange(startKey, endKey string) []string {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	nodes := []string{}
+	start, end := ch.hash(startKey), ch.hash(endKey)
+	if len(ch.keys) == 0 || start == end {
+		return nodes
+	}
+
+	seen := make(map[string]bool)
+	add := func(idx int) {
+		if node := ch.hashMap[ch.keys[idx%len(ch.keys)]]; !seen[node] {
+			seen[node] = true
+			nodes = append(nodes, node)
+		}
+	}
+
+	// 区間内にある仮想ノードはそれぞれ直前までの位置を担当する
+	// uint32の引き算で回り込みを扱う（区間内ならk-start < end-start）
+	first := ch.search(start)
+	for i := 0; i < len(ch.keys); i++ {
+		idx := (first + i) % len(ch.keys)
+		if ch.keys[idx]-start >= end-start {
+			break
+		}
+		add(idx)
+	}
+
+	// 区間の最後の位置(end-1)は、区間外の次の仮想ノードが担当することがある
+	add(ch.search(end - 1))
+	return nodes
+}
+
+// GetMany は複数のキーの担当ノードをまとめて取得（キー → ノード）
+// 読み込みロックを1回だけ取るため、キーごとにGetを呼ぶより競合が少ない
+// 重複したキーは1つにまとめられ、ノードがない場合は全てのキーが空文字列になる
+func (ch *ConsistentHash) GetMany(keys []string) map[string]string {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	result := make(map[string]string, len(keys))
+	for _, key := range keys {
+		result[key] = ch.get(key)
+	}
+	return result
+}
+
+// Walk はリング上の仮想ノードの位置を時計回り（ハッシュ値の昇順）に辿り、位置と担当ノードをfnに渡す
+// 可視化やデバッグ用。読み込みロックの下で複製を取ってから呼び出すため、
+// fnの中からリングのメソッドを呼んだりノードを追加・削除したりしてもよい
+func (ch *ConsistentHash) Walk(fn func(hash uint32, node string)) {
+	ch.mu.RLock()
+	keys := append([]uint32{}, ch.keys...)
+	nodes := make([]string, len(keys))
+	for i, hash := range keys {
+		nodes[i] = ch.hashMap[hash]
+	}
+	ch.mu.RUnlock()
+
+	for i, hash := range keys {
+		fn(hash, nodes[i])
+	}
+}
+
+// walk はhashの位置から時計回りにリングを辿り、物理ノードを重複なしで順に渡す
+// fnがfalseを返すか全ノードを辿り終えると終了する
+func (ch *ConsistentHash) walk(hash uint32, fn func(node string) bool) {
+	if len(ch.keys) == 0 {
+		return
+	}
+
+	start := ch.search(hash)
+	seen := make(map[string]bool)
+	for i := 0; i < len(ch.keys); i++ {
+		node := ch.hashMap[ch.keys[(start+i)%len(ch.keys)]]
+		if seen[node] {
+			continue
+		}
+		seen[node] = true
+		if !fn(node) {
+			return
+		}
+	}
+}
+
+// GetN はキーの位置から時計回りに辿って、異なる物理ノードを最大n個返す
+// 先頭が担当ノード（Getと同じ）で、以降がレプリカの配置先になる
+// 同じ物理ノードの仮想ノードは飛ばし、リングの末尾からは先頭に戻る
+// 物理ノードがn個未満の場合のみ、返すノードはn個より少なくなる
+func (ch *ConsistentHash) GetN(key string, n int) []string {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	nodes := make([]string, 0, max(n, 0))
+	if n <= 0 {
+		return nodes
+	}
+
+	ch.walk(ch.hash(key), func(node string) bool {
+		nodes = append(nodes, node)
+		return len(nodes) < n
+	})
+	return nodes
+}
+
+// GetCapped はキー数の上限を考慮してノードを取得
+// counts: 各ノードが現在保持しているキー数（呼び出し側が管理・更新する）
+// limit: 1ノードあたりの絶対的な上限
+// 時計回りに辿り、保持数がlimitに達しているノードは飛ばして次のノードへ溢れさせる
+// 全ノードが上限に達している場合はok=falseを返す
+func (ch *ConsistentHash) GetCapped(key string, counts map[string]int, limit int) (node string, ok bool) {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	ch.walk(ch.hash(key), func(n string) bool {
+		if counts[n] < limit {
+			node, ok = n, true
+			return false
+		}
+		return true
+	})
+	return node, ok
+}
+
+// GetBounded は負荷の上限付きコンシステントハッシュ（Consistent Hashing with Bounded Loads）でノードを取得
+// loads: 各ノードが現在保持しているキー数（呼び出し側が管理・更新する）
+// capacity: 平均負荷に対する許容倍率（例: 1.25なら平均の1.25倍まで）
+// 上限はceil(capacity × (総負荷+1) / ノード数)で、配置しようとしているキーも平均に含める
+// 時計回りに辿り、負荷が上限に達しているノードは飛ばして次のノードへ溢れさせる
+// capacityが1未満の場合は1として扱うため、ノードがあれば必ずいずれかに割り当てられる
+// ノードがない場合は空文字列を返す
+func (ch *ConsistentHash) GetBounded(key string, loads map[string]int, capacity float64) string {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	if len(ch.nodeHashes) == 0 {
+		return ""
+	}
+
+	total := 1
+	for node := range ch.nodeHashes {
+		total += loads[node]
+	}
+	bound := int(math.Ceil(max(capacity, 1) * float64(total) / float64(len(ch.nodeHashes))))
+
+	var node string
+	ch.walk(ch.hash(key), func(n string) bool {
+		if loads[n] < bound {
+			node = n
+			return false
+		}
+		return true
+	})
+	return node
+}
+
+// GetStablePair はキーの担当ノード(primary)とバックアップノード(backup)を取得
+// backupはprimaryから時計回りに辿って最初に現れる、primaryとは異なる物理ノードと定義する
+// この定義により、primaryとbackupの間以外の位置にノードが参加・離脱しても組は変わらない
+// 一方、キーの位置からbackupの仮想ノードまでの間に新しいノードが参加すると、
+// そのノードがprimaryまたはbackupになる（コンシステントハッシュの本質的な性質）
+// ノードが1つしかない場合backupは空文字列になる
+func (ch *ConsistentHash) GetStablePair(key string) (primary, backup string) {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	ch.walk(ch.hash(key), func(node string) bool {
+		if primary == "" {
+			primary = node
+			return true
+		}
+		backup = node
+		return false
+	})
+	return primary, backup
+}
+
+// Placement はキーの配置を説明するトレース情報
+type Placement struct {
+	Key          string // 対象のキー
+	KeyHash      uint32 // キーのハッシュ値
+	Index        int    // 探索で到達したリング上のインデックス
+	Wrapped      bool   // リングの末尾を超えて先頭に戻ったか
+	VirtualHash  uint32 // 一致した仮想ノードのハッシュ値（リング上の位置）
+	VirtualNode  string // 一致した仮想ノード名
+	PhysicalNode string // 担当する物理ノード
+}
+
+// String はトレース情報を1行の文字列にする
+func (p Placement) String() string {
+	if p.PhysicalNode == "" {
+		return fmt.Sprintf("key=%q hash=%d -> (empty ring)", p.Key, p.KeyHash)
+	}
+	return fmt.Sprintf("key=%q hash=%d -> index=%d wrapped=%v vnode=%q(%d) -> node=%q",
+		p.Key, p.KeyHash, p.Index, p.Wrapped, p.VirtualNode, p.VirtualHash, p.PhysicalNode)
+}
+
+// Explain はキーがなぜそのノードに割り当てられたかを説明する
+// キーのハッシュ値、探索したリング上の位置、一致した仮想ノード、物理ノードを返す
+func (ch *ConsistentHash) Explain(key string) Placement {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	p := Placement{Key: key, KeyHash: ch.hash(key)}
+	if len(ch.keys) == 0 {
+		return p
+	}
+
+	p.Index = ch.search(p.KeyHash)
+	if p.Index == len(ch.keys) {
+		p.Index = 0
+		p.Wrapped = true
+	}
+	p.VirtualHash = ch.keys[p.Index]
+	p.PhysicalNode = ch.hashMap[p.VirtualHash]
+
+	// 仮想ノード名は保持していないため、記録したハッシュ値からレプリカ番号を復元
+	// （衝突を解決した仮想ノードでは、VirtualHashは仮想ノード名のハッシュ値からずれている）
+	for i, hash := range ch.nodeHashes[p.PhysicalNode] {
+		if hash == p.VirtualHash {
+			p.VirtualNode = p.PhysicalNode + "#" + strconv.Itoa(i)
+			break
+		}
+	}
+
+	return p
+}
+
+// Generation は現在のリングの世代番号を返す
+// Add/Removeのたびに単調増加する
+func (ch *ConsistentHash) Generation() uint64 {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return ch.gen
+}
+
+// GetAtGeneration は呼び出し側が把握している世代gen時点でのルックアップを行う
+// genが現在の世代より古い場合はリングが変化しているため、ok=falseを返す
+// （呼び出し側はGenerationを取り直して再試行する）
+func (ch *ConsistentHash) GetAtGeneration(key string, gen uint64) (node string, ok bool) {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	if gen < ch.gen {
+		return "", false
+	}
+	return ch.get(key), true
+}
+
+// GetNodes は現在登録されている全ノードのリストを取得
+func (ch *ConsistentHash) GetNodes() []string {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return ch.nodes()
+}
+
+// nodes はロックを取らずにGetNodesを行う（呼び出し側がロックを保持すること）
+func (ch *ConsistentHash) nodes() []string {
+	nodes := make([]string, 0, len(ch.nodeHashes))
+	for node := range ch.nodeHashes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// Contains はノードがリングに登録されているかを返す
+func (ch *ConsistentHash) Contains(node string) bool {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	_, ok := ch.nodeHashes[node]
+	return ok
+}
+
+// NodeCount は登録されている物理ノード数を返す（GetNodesと違いスライスを確保しない）
+func (ch *ConsistentHash) NodeCount() int {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	return len(ch.nodeHashes)
+}
+
+// StatsJSON はリングの統計情報をJSONで返す
+// node_count: 物理ノード数, replicas: 仮想ノード数, entries: リング上の位置の数,
+// max_gap: 隣り合う位置の最大間隔（リングの末尾から先頭への区間を含むため、位置が1つなら2^32）
+func (ch *ConsistentHash) StatsJSON() ([]byte, error) {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	var maxGap uint64
+	if len(ch.keys) > 0 {
+		// 末尾から先頭への区間は最大2^32になり32ビットのintを溢れるため、uint64で計算する
+		maxGap = uint64(ch.keys[0]) + 1<<32 - uint64(ch.keys[len(ch.keys)-1])
+		for i := 1; i < len(ch.keys); i++ {
+			maxGap = max(maxGap, uint64(ch.keys[i]-ch.keys[i-1]))
+		}
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"node_count": len(ch.nodeHashes),
+		"replicas":   ch.replicas,
+		"entries":    len(ch.keys),
+		"max_gap":    maxGap,
+	})
+}
+
+// Distribution はkeysをルーティングした場合の各ノードの担当キー数を返す
+// キーが1つも割り当てられないノードも0として含める
+func (ch *ConsistentHash) Distribution(keys []string) map[string]int {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	counts := make(map[string]int, len(ch.nodeHashes))
+	for node := range ch.nodeHashes {
+		counts[node] = 0
+	}
+	if len(ch.keys) == 0 {
+		return counts
+	}
+	for _, key := range keys {
+		counts[ch.get(key)]++
+	}
+	return counts
+}
+
+// ArcSizes は各物理ノードが担当するハッシュ空間の割合を返す（合計は1）
+// 仮想ノードは直前の仮想ノードの位置から自身の位置までの区間を担当するため、
+// 各仮想ノードの区間の長さを物理ノードごとに合計して2^32で割る
+// replicasを調整する際に、割合が均等に近いかを確認するのに使う
+func (ch *ConsistentHash) ArcSizes() map[string]float64 {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	sizes := make(map[string]float64, len(ch.nodeHashes))
+	for i, hash := range ch.keys {
+		// 先頭の仮想ノードはリングの末尾から先頭に戻る区間を担当する
+		prev := ch.keys[(i+len(ch.keys)-1)%len(ch.keys)]
+		arc := uint64(hash - prev) // uint32の引き算は2^32を法として回り込む
+		if arc == 0 {
+			arc = 1 << 32 // 仮想ノードが1つだけなら空間全体
+		}
+		sizes[ch.hashMap[hash]] += float64(arc) / (1 << 32)
+	}
+	return sizes
+}
@@ -0,0 +1,794 @@
+package consistenthash
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRemoveDeletesExactlyItsVirtualNodes(t *testing.T) {
+	ch := New(10)
+	ch.Add("a", "b", "c")
+	ch.AddWeighted("d", 3)
+
+	ch.Remove("b")
+	if got, want := ch.GetNodes(), []string{"a", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetNodes() = %v, want %v", got, want)
+	}
+	if got, want := len(ch.keys), 10*2+10*3; got != want {
+		t.Errorf("ring has %d positions, want %d", got, want)
+	}
+
+	ch.Remove("d")
+	if got, want := len(ch.keys), 10*2; got != want {
+		t.Errorf("ring has %d positions after removing weighted node, want %d", got, want)
+	}
+	if len(ch.hashMap) != len(ch.keys) {
+		t.Errorf("hashMap has %d entries, keys has %d", len(ch.hashMap), len(ch.keys))
+	}
+}
+
+func TestNonPositiveReplicasDoNotPanic(t *testing.T) {
+	for _, replicas := range []int{0, -1} {
+		ch := New(replicas)
+		ch.Add("a")
+		if got := ch.Get("key"); got != "" {
+			t.Errorf("New(%d): Get = %q, want empty string", replicas, got)
+		}
+	}
+}
+
+func TestGetAtGenerationFlagsStaleReads(t *testing.T) {
+	ch := New(10)
+	ch.Add("a", "b")
+
+	gen := ch.Generation()
+	if node, ok := ch.GetAtGeneration("key", gen); !ok || node != ch.Get("key") {
+		t.Fatalf("GetAtGeneration(current) = (%q, %v), want (%q, true)", node, ok, ch.Get("key"))
+	}
+
+	ch.Add("c")
+	afterAdd := ch.Generation()
+	if afterAdd <= gen {
+		t.Fatalf("Generation() = %d after Add, want > %d", afterAdd, gen)
+	}
+	if _, ok := ch.GetAtGeneration("key", gen); ok {
+		t.Error("GetAtGeneration with a generation older than Add was not flagged")
+	}
+
+	ch.Remove("a")
+	if ch.Generation() <= afterAdd {
+		t.Fatalf("Generation() = %d after Remove, want > %d", ch.Generation(), afterAdd)
+	}
+	if _, ok := ch.GetAtGeneration("key", afterAdd); ok {
+		t.Error("GetAtGeneration with a generation older than Remove was not flagged")
+	}
+	if node, ok := ch.GetAtGeneration("key", ch.Generation()); !ok || node != ch.Get("key") {
+		t.Errorf("GetAtGeneration(refreshed) = (%q, %v), want (%q, true)", node, ok, ch.Get("key"))
+	}
+}
+
+func TestGetCappedOverflowsThenRejects(t *testing.T) {
+	ch := New(10)
+	ch.Add("a", "b", "c")
+	const limit = 2
+
+	counts := map[string]int{}
+	for i := range 3 * limit {
+		key := "key" + strconv.Itoa(i)
+		node, ok := ch.GetCapped(key, counts, limit)
+		if !ok {
+			t.Fatalf("GetCapped(%q) rejected with only %d of %d slots used", key, i, 3*limit)
+		}
+		if counts[node] >= limit {
+			t.Fatalf("GetCapped(%q) = %q which is already at the cap", key, node)
+		}
+		// 担当ノードが上限に達していれば、時計回りで上限未満の最初のノードへ溢れる
+		var want string
+		for _, n := range ch.GetN(key, 3) {
+			if counts[n] < limit {
+				want = n
+				break
+			}
+		}
+		if node != want {
+			t.Fatalf("GetCapped(%q) = %q, want first non-full node %q", key, node, want)
+		}
+		counts[node]++
+	}
+
+	if node, ok := ch.GetCapped("one-more", counts, limit); ok {
+		t.Errorf("GetCapped with every node full = (%q, true), want rejection", node)
+	}
+}
+
+func TestExplainMatchesGet(t *testing.T) {
+	ch := New(20)
+	ch.Add("a", "b", "c")
+
+	for i := range 200 {
+		key := "key" + strconv.Itoa(i)
+		p := ch.Explain(key)
+		if p.PhysicalNode != ch.Get(key) {
+			t.Fatalf("Explain(%q).PhysicalNode = %q, Get = %q", key, p.PhysicalNode, ch.Get(key))
+		}
+		if p.KeyHash != ch.hash(key) || p.VirtualHash != ch.keys[p.Index] {
+			t.Fatalf("Explain(%q) = %+v is inconsistent with the ring", key, p)
+		}
+		// 到達した位置はキーのハッシュ以上で最小の仮想ノード（なければ先頭に戻る）
+		if p.Wrapped {
+			if p.Index != 0 || p.KeyHash <= ch.keys[len(ch.keys)-1] {
+				t.Fatalf("Explain(%q) wrapped although a later position exists", key)
+			}
+		} else if p.VirtualHash < p.KeyHash || (p.Index > 0 && ch.keys[p.Index-1] >= p.KeyHash) {
+			t.Fatalf("Explain(%q) index %d is not the clockwise successor", key, p.Index)
+		}
+		if !strings.HasPrefix(p.VirtualNode, p.PhysicalNode+"#") {
+			t.Fatalf("Explain(%q).VirtualNode = %q, want prefix %q", key, p.VirtualNode, p.PhysicalNode+"#")
+		}
+	}
+
+	if p := New(10).Explain("key"); p.PhysicalNode != "" {
+		t.Errorf("Explain on an empty ring = %+v, want no node", p)
+	}
+}
+
+// stubHasher は登録した文字列だけを指定の位置に置くハッシュ関数を返す
+// 仮想ノードやキーの位置を固定してリングの配置を手で計算できるようにする
+func stubHasher(positions map[string]uint64) func([]byte) uint64 {
+	return func(data []byte) uint64 {
+		pos, ok := positions[string(data)]
+		if !ok {
+			panic("stubHasher: no position for " + string(data))
+		}
+		return pos
+	}
+}
+
+func TestGetStablePairContract(t *testing.T) {
+	ch := NewWithHasher(1, stubHasher(map[string]uint64{
+		"key": 150,
+		"a#0": 100, "b#0": 200, "c#0": 300,
+		"far#0": 400, "between#0": 250, "before#0": 170,
+	}))
+	ch.Add("a", "b", "c")
+
+	check := func(when, wantPrimary, wantBackup string) {
+		t.Helper()
+		if primary, backup := ch.GetStablePair("key"); primary != wantPrimary || backup != wantBackup {
+			t.Errorf("%s: GetStablePair = (%q, %q), want (%q, %q)", when, primary, backup, wantPrimary, wantBackup)
+		}
+	}
+	check("initial", "b", "c")
+
+	// 組の外側に参加したノードは組を変えない
+	ch.Add("far")
+	check("after join elsewhere", "b", "c")
+
+	// primaryとbackupの間に参加したノードは新しいbackupになる
+	ch.Add("between")
+	check("after join between primary and backup", "b", "between")
+
+	// キーとprimaryの間に参加したノードは新しいprimaryになり、元のprimaryがbackupになる
+	ch.Add("before")
+	check("after join before primary", "before", "b")
+
+	single := New(10)
+	single.Add("only")
+	if primary, backup := single.GetStablePair("key"); primary != "only" || backup != "" {
+		t.Errorf("single node: GetStablePair = (%q, %q), want (\"only\", \"\")", primary, backup)
+	}
+}
+
+func TestStatsJSONReportsRingShape(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []string
+		want  map[string]float64
+	}{
+		// 最大の隙間はbの位置から末尾を回ってaに戻るまで
+		{"two nodes", []string{"a", "b"}, map[string]float64{"node_count": 2, "replicas": 1, "entries": 2, "max_gap": 1<<32 - 900}},
+		// 位置が1つだけなら、末尾を回って自分に戻る隙間はちょうど2^32
+		{"single node", []string{"a"}, map[string]float64{"node_count": 1, "replicas": 1, "entries": 1, "max_gap": 1 << 32}},
+		{"empty", nil, map[string]float64{"node_count": 0, "replicas": 1, "entries": 0, "max_gap": 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := NewWithHasher(1, stubHasher(map[string]uint64{"a#0": 100, "b#0": 1000}))
+			ch.Add(tt.nodes...)
+
+			data, err := ch.StatsJSON()
+			if err != nil {
+				t.Fatalf("StatsJSON: %v", err)
+			}
+			var got map[string]float64
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("StatsJSON output is not a JSON object of numbers: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StatsJSON = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// signedFoldHasher は符号付きバイトをシフトして結合し、負数を反転する旧方式のハッシュ
+// 下位バイトの符号拡張で上位ビットが潰れるため、値の多くが0付近に集まる
+func signedFoldHasher(data []byte) uint64 {
+	sum := sha1.Sum(data)
+	h := int64(int32(int8(sum[0]))<<24 | int32(int8(sum[1]))<<16 | int32(int8(sum[2]))<<8 | int32(int8(sum[3])))
+	if h < 0 {
+		h = -h
+	}
+	return uint64(h)
+}
+
+// loadChiSquare はキーを振り分けたときの各ノードの負荷の、一様分布からのカイ二乗値を返す
+func loadChiSquare(ch *ConsistentHash, nodes []string, keys []string) float64 {
+	dist := ch.Distribution(keys)
+	mean := float64(len(keys)) / float64(len(nodes))
+	chi := 0.0
+	for _, node := range nodes {
+		d := float64(dist[node]) - mean
+		chi += d * d / mean
+	}
+	return chi
+}
+
+func TestUnsignedHashDistributesCloserToUniform(t *testing.T) {
+	nodes := []string{"a", "b", "c", "d", "e"}
+	keys := make([]string, 10000)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+
+	unsigned := New(160)
+	unsigned.Add(nodes...)
+	signed := NewWithHasher(160, signedFoldHasher)
+	signed.Add(nodes...)
+
+	u, s := loadChiSquare(unsigned, nodes, keys), loadChiSquare(signed, nodes, keys)
+	if u >= s {
+		t.Errorf("chi-square unsigned = %.1f, signed = %.1f; want unsigned closer to uniform", u, s)
+	}
+
+	// 各ノードの負荷は平均の±15%以内
+	mean := len(keys) / len(nodes)
+	for node, n := range unsigned.Distribution(keys) {
+		if n < mean*85/100 || n > mean*115/100 {
+			t.Errorf("node %s got %d keys, want within 15%% of %d", node, n, mean)
+		}
+	}
+}
+
+func TestGetNWrapsAroundSkippingDuplicates(t *testing.T) {
+	// 時計回りに a#0=100, b#1=120, b#0=200, key=250, c#0=300, c#1=310, a#1=350
+	ch := NewWithHasher(2, stubHasher(map[string]uint64{
+		"key": 250,
+		"a#0": 100, "a#1": 350,
+		"b#0": 200, "b#1": 120,
+		"c#0": 300, "c#1": 310,
+	}))
+	ch.Add("a", "b", "c")
+
+	tests := []struct {
+		n    int
+		want []string
+	}{
+		{0, []string{}},
+		{1, []string{"c"}},
+		{2, []string{"c", "a"}},
+		{3, []string{"c", "a", "b"}},
+		{5, []string{"c", "a", "b"}},
+	}
+	for _, tt := range tests {
+		if got := ch.GetN("key", tt.n); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetN(key, %d) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestGetNReturnsDistinctNodes(t *testing.T) {
+	ch := New(50)
+	ch.Add("a", "b", "c", "d", "e")
+
+	for i := 0; i < 1000; i++ {
+		key := "key" + strconv.Itoa(i)
+		nodes := ch.GetN(key, 3)
+		if len(nodes) != 3 {
+			t.Fatalf("GetN(%q, 3) returned %d nodes", key, len(nodes))
+		}
+		if nodes[0] != ch.Get(key) {
+			t.Errorf("GetN(%q, 3)[0] = %q, want Get = %q", key, nodes[0], ch.Get(key))
+		}
+		if nodes[0] == nodes[1] || nodes[1] == nodes[2] || nodes[0] == nodes[2] {
+			t.Errorf("GetN(%q, 3) = %v has duplicates", key, nodes)
+		}
+	}
+}
+
+func TestConcurrentAddRemoveGet(t *testing.T) {
+	ch := New(20)
+	ch.Add("a", "b", "c") // 常に登録されているノード
+
+	// Getが返してよいのは登録されたことのあるノードのみ
+	valid := map[string]bool{"a": true, "b": true, "c": true, "x": true, "y": true}
+
+	var wg sync.WaitGroup
+	for _, node := range []string{"x", "y"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				ch.Add(node)
+				ch.Remove(node)
+			}
+		}()
+	}
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := "key" + strconv.Itoa(i)
+				if node := ch.Get(key); !valid[node] {
+					t.Errorf("Get(%q) = %q, not a registered node", key, node)
+					return
+				}
+				ch.GetNodes()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := ch.GetNodes(), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetNodes() = %v, want %v", got, want)
+	}
+}
+
+func TestAddWeightedDoublesShare(t *testing.T) {
+	ch := New(100)
+	ch.Add("a", "b")
+	ch.AddWeighted("heavy", 2)
+
+	keys := make([]string, 20000)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+	dist := ch.Distribution(keys)
+
+	// 重み2のノードは重み1のノードの平均のおよそ2倍（1.6〜2.4倍）のキーを受け持つ
+	light := float64(dist["a"]+dist["b"]) / 2
+	ratio := float64(dist["heavy"]) / light
+	if ratio < 1.6 || ratio > 2.4 {
+		t.Errorf("heavy/light ratio = %.2f (dist %v), want about 2", ratio, dist)
+	}
+}
+
+func TestVirtualNodeCollisionKeepsBothNodes(t *testing.T) {
+	// a#0とb#0は同じ位置100に衝突し、後から追加したbは101に置かれる
+	ch := NewWithHasher(1, stubHasher(map[string]uint64{
+		"a#0": 100, "b#0": 100,
+		"k100": 100, "k101": 101,
+	}))
+	ch.Add("a")
+	ch.Add("b")
+
+	if got, want := len(ch.keys), 2; got != want {
+		t.Fatalf("ring has %d positions, want %d", got, want)
+	}
+	if got := ch.Get("k100"); got != "a" {
+		t.Errorf("Get(k100) = %q, want a", got)
+	}
+	if got := ch.Get("k101"); got != "b" {
+		t.Errorf("Get(k101) = %q, want b", got)
+	}
+
+	// 衝突を解決した位置が記録されているため、Removeはその位置を正しく削除する
+	ch.Remove("a")
+	if got := ch.Get("k100"); got != "b" {
+		t.Errorf("Get(k100) after Remove(a) = %q, want b", got)
+	}
+	if got, want := ch.keys, []uint32{101}; !reflect.DeepEqual(got, want) {
+		t.Errorf("keys after Remove(a) = %v, want %v", got, want)
+	}
+	ch.Remove("b")
+	if len(ch.keys) != 0 || len(ch.hashMap) != 0 {
+		t.Errorf("ring not empty after removing both nodes: keys=%v hashMap=%v", ch.keys, ch.hashMap)
+	}
+}
+
+func TestContainsAndNodeCount(t *testing.T) {
+	ch := New(10)
+	if ch.Contains("a") || ch.NodeCount() != 0 {
+		t.Fatalf("empty ring: Contains(a) = %v, NodeCount = %d", ch.Contains("a"), ch.NodeCount())
+	}
+
+	steps := []struct {
+		op    func()
+		count int
+		in    []string
+		out   []string
+	}{
+		{func() { ch.Add("a", "b") }, 2, []string{"a", "b"}, []string{"c"}},
+		{func() { ch.Add("a") }, 2, []string{"a", "b"}, []string{"c"}}, // 再追加では増えない
+		{func() { ch.AddWeighted("c", 2) }, 3, []string{"a", "b", "c"}, nil},
+		{func() { ch.Remove("a") }, 2, []string{"b", "c"}, []string{"a"}},
+		{func() { ch.Remove("missing") }, 2, []string{"b", "c"}, []string{"missing"}},
+		{func() { ch.Remove("b"); ch.Remove("c") }, 0, nil, []string{"a", "b", "c"}},
+	}
+	for i, s := range steps {
+		s.op()
+		if got := ch.NodeCount(); got != s.count {
+			t.Errorf("step %d: NodeCount = %d, want %d", i, got, s.count)
+		}
+		for _, node := range s.in {
+			if !ch.Contains(node) {
+				t.Errorf("step %d: Contains(%q) = false, want true", i, node)
+			}
+		}
+		for _, node := range s.out {
+			if ch.Contains(node) {
+				t.Errorf("step %d: Contains(%q) = true, want false", i, node)
+			}
+		}
+	}
+}
+
+func TestArcSizesSumToOne(t *testing.T) {
+	ch := New(50)
+	ch.Add("a", "b", "c")
+	ch.AddWeighted("d", 2)
+
+	sum := 0.0
+	for _, size := range ch.ArcSizes() {
+		sum += size
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("arc sizes sum to %v, want 1", sum)
+	}
+
+	// 仮想ノードが1つだけなら空間全体を担当する
+	single := New(1)
+	single.Add("a")
+	if got := single.ArcSizes()["a"]; got != 1 {
+		t.Errorf("single virtual node arc = %v, want 1", got)
+	}
+}
+
+func TestArcSizesOnStubRing(t *testing.T) {
+	// aは(3/4·2^32, 2^32)と[0, 1/4·2^32]を、bは(1/4·2^32, 3/4·2^32]を担当する
+	ch := NewWithHasher(1, stubHasher(map[string]uint64{
+		"a#0": 1 << 30, "b#0": 3 << 30,
+	}))
+	ch.Add("a", "b")
+	if got, want := ch.ArcSizes(), map[string]float64{"a": 0.5, "b": 0.5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ArcSizes() = %v, want %v", got, want)
+	}
+}
+
+func TestDistributionCountsEveryKey(t *testing.T) {
+	// k1, k2は(10, 1000]にあるのでa、k3は末尾から先頭に戻ってbが担当する
+	ch := NewWithHasher(1, stubHasher(map[string]uint64{
+		"a#0": 1000, "b#0": 10,
+		"k1": 500, "k2": 600, "k3": 2000,
+	}))
+	ch.Add("a", "b")
+
+	got := ch.Distribution([]string{"k1", "k2", "k3"})
+	if want := map[string]int{"a": 2, "b": 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Distribution = %v, want %v", got, want)
+	}
+	if got, want := ch.Distribution(nil), map[string]int{"a": 0, "b": 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Distribution(nil) = %v, want %v", got, want)
+	}
+}
+
+func TestGetBoundedRespectsCapacity(t *testing.T) {
+	// 仮想ノードが少ないリングでは通常のGetの負荷が大きく偏る
+	nodes := []string{"a", "b", "c", "d"}
+	ch := New(3)
+	ch.Add(nodes...)
+
+	const numKeys, capacity = 4000, 1.25
+	bound := int(math.Ceil(capacity * numKeys / float64(len(nodes))))
+
+	loads := map[string]int{}
+	for i := 0; i < numKeys; i++ {
+		node := ch.GetBounded("key"+strconv.Itoa(i), loads, capacity)
+		loads[node]++
+	}
+	for _, node := range nodes {
+		if loads[node] > bound {
+			t.Errorf("GetBounded: node %s has %d keys, bound %d", node, loads[node], bound)
+		}
+	}
+
+	plain := map[string]int{}
+	for i := 0; i < numKeys; i++ {
+		plain[ch.Get("key"+strconv.Itoa(i))]++
+	}
+	exceeded := false
+	for _, node := range nodes {
+		exceeded = exceeded || plain[node] > bound
+	}
+	if !exceeded {
+		t.Errorf("plain Get loads %v stay within bound %d; fixture is not skewed", plain, bound)
+	}
+
+	if got := New(3).GetBounded("key", loads, capacity); got != "" {
+		t.Errorf("GetBounded on an empty ring = %q, want empty string", got)
+	}
+}
+
+func TestGetManyMatchesGet(t *testing.T) {
+	ch := New(20)
+	ch.Add("a", "b", "c")
+
+	keys := []string{"dup", "dup"} // 重複したキーは1つにまとめられる
+	for i := 0; i < 500; i++ {
+		keys = append(keys, "key"+strconv.Itoa(i))
+	}
+	got := ch.GetMany(keys)
+	if len(got) != len(keys)-1 {
+		t.Errorf("GetMany returned %d entries, want %d", len(got), len(keys)-1)
+	}
+	for _, key := range keys {
+		if want := ch.Get(key); got[key] != want {
+			t.Errorf("GetMany[%q] = %q, want Get = %q", key, got[key], want)
+		}
+	}
+
+	if got, want := New(20).GetMany([]string{"x"}), map[string]string{"x": ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetMany on an empty ring = %v, want %v", got, want)
+	}
+}
+
+func TestRemoveChecked(t *testing.T) {
+	ch := New(10)
+	ch.Add("a", "b")
+
+	if err := ch.RemoveChecked("a"); err != nil {
+		t.Errorf("RemoveChecked(a) = %v, want nil", err)
+	}
+	if ch.Contains("a") {
+		t.Error("a still registered after RemoveChecked")
+	}
+	for _, node := range []string{"a", "missing"} {
+		if err := ch.RemoveChecked(node); err == nil {
+			t.Errorf("RemoveChecked(%q) = nil, want error", node)
+		}
+	}
+	if got, want := ch.GetNodes(), []string{"b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetNodes() = %v, want %v", got, want)
+	}
+}
+
+func TestWalkVisitsSortedRegisteredPositions(t *testing.T) {
+	ch := New(10)
+	ch.Add("a", "b", "c")
+	ch.Remove("b")
+
+	var hashes []uint32
+	counts := map[string]int{}
+	ch.Walk(func(hash uint32, node string) {
+		hashes = append(hashes, hash)
+		counts[node]++
+	})
+
+	if len(hashes) != 20 {
+		t.Fatalf("Walk visited %d positions, want 20", len(hashes))
+	}
+	for i := 1; i < len(hashes); i++ {
+		if hashes[i-1] >= hashes[i] {
+			t.Fatalf("positions not strictly increasing at %d: %d, %d", i, hashes[i-1], hashes[i])
+		}
+	}
+	if want := map[string]int{"a": 10, "c": 10}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("Walk visited nodes %v, want %v", counts, want)
+	}
+
+	// fnの中からリングを変更してもデッドロックしない
+	ch.Walk(func(hash uint32, node string) { ch.Remove(node) })
+	if ch.NodeCount() != 0 {
+		t.Errorf("NodeCount = %d after removing every node during Walk", ch.NodeCount())
+	}
+}
+
+func TestGetWithPositionReturnsClockwiseSuccessor(t *testing.T) {
+	ch := NewWithHasher(1, stubHasher(map[string]uint64{
+		"a#0": 100, "b#0": 200,
+		"k50": 50, "k100": 100, "k150": 150, "k250": 250,
+	}))
+	if node, pos, found := ch.GetWithPosition("k50"); found || node != "" || pos != 0 {
+		t.Errorf("empty ring: GetWithPosition = %q, %d, %v, want \"\", 0, false", node, pos, found)
+	}
+	ch.Add("a", "b")
+
+	tests := []struct {
+		key  string
+		node string
+		pos  uint32
+	}{
+		{"k50", "a", 100},
+		{"k100", "a", 100}, // 同じ位置の仮想ノードが担当する
+		{"k150", "b", 200},
+		{"k250", "a", 100}, // 末尾を超えると先頭に戻る
+	}
+	for _, tt := range tests {
+		node, pos, found := ch.GetWithPosition(tt.key)
+		if !found || node != tt.node || pos != tt.pos {
+			t.Errorf("GetWithPosition(%q) = %q, %d, %v, want %q, %d, true", tt.key, node, pos, found, tt.node, tt.pos)
+		}
+	}
+}
+
+func TestGetWithPositionMatchesRing(t *testing.T) {
+	ch := New(20)
+	ch.Add("a", "b", "c")
+
+	for i := 0; i < 500; i++ {
+		key := "key" + strconv.Itoa(i)
+		node, pos, found := ch.GetWithPosition(key)
+		if !found || node != ch.Get(key) || ch.hashMap[pos] != node {
+			t.Fatalf("GetWithPosition(%q) = %q, %d, %v; Get = %q", key, node, pos, found, ch.Get(key))
+		}
+		// posはキーのハッシュ値以上の最小の位置（なければ先頭の位置）
+		h := ch.hash(key)
+		want := ch.keys[0]
+		for _, k := range ch.keys {
+			if k >= h {
+				want = k
+				break
+			}
+		}
+		if pos != want {
+			t.Errorf("GetWithPosition(%q) position = %d, want %d", key, pos, want)
+		}
+	}
+}
+
+func TestNewChecked(t *testing.T) {
+	for _, replicas := range []int{0, -1} {
+		if ch, err := NewChecked(replicas); err == nil || ch != nil {
+			t.Errorf("NewChecked(%d) = %v, %v, want nil and an error", replicas, ch, err)
+		}
+	}
+
+	ch, err := NewChecked(7)
+	if err != nil {
+		t.Fatalf("NewChecked(7): %v", err)
+	}
+	if got := ch.Replicas(); got != 7 {
+		t.Errorf("Replicas() = %d, want 7", got)
+	}
+	ch.Add("a")
+	if got, want := len(ch.keys), 7; got != want {
+		t.Errorf("ring has %d positions, want %d", got, want)
+	}
+}
+
+func TestAddWithMetaRoundTrip(t *testing.T) {
+	type addr struct{ Host, DC string }
+	ch := New(20)
+	ch.AddWithMeta("a", addr{"10.0.0.1", "tokyo"})
+	ch.AddWithMeta("b", addr{"10.0.0.2", "osaka"})
+	ch.Add("c") // メタデータなし
+
+	want := map[string]any{"a": addr{"10.0.0.1", "tokyo"}, "b": addr{"10.0.0.2", "osaka"}, "c": nil}
+	for i := 0; i < 200; i++ {
+		key := "key" + strconv.Itoa(i)
+		node, meta := ch.GetMeta(key)
+		if node != ch.Get(key) {
+			t.Fatalf("GetMeta(%q) node = %q, want Get = %q", key, node, ch.Get(key))
+		}
+		if meta != want[node] {
+			t.Errorf("GetMeta(%q) meta = %v, want %v", key, meta, want[node])
+		}
+	}
+
+	// 置き換えたメタデータが返り、Removeでメタデータも削除される
+	ch.AddWithMeta("a", addr{"10.0.0.9", "tokyo"})
+	ch.Remove("b")
+	ch.Remove("c")
+	if node, meta := ch.GetMeta("key"); node != "a" || meta != (addr{"10.0.0.9", "tokyo"}) {
+		t.Errorf("GetMeta(key) = %q, %v, want a with replaced meta", node, meta)
+	}
+	if _, ok := ch.meta["b"]; ok {
+		t.Error("metadata for b remains after Remove")
+	}
+
+	ch.Remove("a")
+	if node, meta := ch.GetMeta("key"); node != "" || meta != nil {
+		t.Errorf("GetMeta on an empty ring = %q, %v, want \"\", nil", node, meta)
+	}
+	if len(ch.meta) != 0 {
+		t.Errorf("meta = %v after removing every node, want empty", ch.meta)
+	}
+}
+
+func TestAddBatchMatchesRepeatedAdd(t *testing.T) {
+	nodes := nodeNames(20)
+	batch := New(20)
+	batch.AddBatch(nodes...)
+
+	// 追加とGetを交互に行っても、まとめて追加したリングと同じ状態になる
+	repeated := New(20)
+	for _, node := range nodes {
+		repeated.Add(node)
+		repeated.Get("key")
+	}
+	if !reflect.DeepEqual(batch.keys, repeated.keys) {
+		t.Fatal("AddBatch and repeated Add built different rings")
+	}
+	if !sort.SliceIsSorted(batch.keys, func(i, j int) bool { return batch.keys[i] < batch.keys[j] }) {
+		t.Error("keys not sorted after AddBatch")
+	}
+	for i := 0; i < 500; i++ {
+		key := "key" + strconv.Itoa(i)
+		if batch.Get(key) != repeated.Get(key) {
+			t.Errorf("Get(%q): batch %q, repeated %q", key, batch.Get(key), repeated.Get(key))
+		}
+	}
+}
+
+// Addを1ノードずつ呼ぶとその度に既存の位置とマージするためO(k·n)になり、
+// AddBatchは全体を1回でマージするため、ノードが多いほど差が大きくなる
+func BenchmarkAddNodes(b *testing.B) {
+	for _, n := range []int{100, 1000} {
+		nodes := nodeNames(n)
+		b.Run("AddBatch/"+strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				ch := New(DefaultReplicas)
+				ch.AddBatch(nodes...)
+			}
+		})
+		b.Run("RepeatedAdd/"+strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				ch := New(DefaultReplicas)
+				for _, node := range nodes {
+					ch.Add(node)
+				}
+			}
+		})
+	}
+}
+
+func TestNodesInRangeOnStubRing(t *testing.T) {
+	// aは(300, 2^32)と[0, 100]を、bは(100, 200]を、cは(200, 300]を担当する
+	ch := NewWithHasher(1, stubHasher(map[string]uint64{
+		"a#0": 100, "b#0": 200, "c#0": 300,
+		"p100": 100, "p101": 101, "p120": 120, "p150": 150,
+		"p180": 180, "p250": 250, "p350": 350,
+	}))
+	if got := ch.NodesInRange("p100", "p150"); len(got) != 0 {
+		t.Errorf("empty ring: NodesInRange = %v, want none", got)
+	}
+	ch.Add("a", "b", "c")
+
+	tests := []struct {
+		start, end string
+		want       []string
+	}{
+		{"p150", "p250", []string{"b", "c"}},
+		{"p150", "p180", []string{"b"}}, // 仮想ノードを含まない区間
+		{"p100", "p101", []string{"a"}}, // 位置100だけの区間
+		{"p100", "p250", []string{"a", "b", "c"}},
+		{"p250", "p120", []string{"c", "a", "b"}}, // 末尾を回り込む区間
+		{"p350", "p101", []string{"a"}},           // 回り込むがaの担当範囲に収まる
+		{"p150", "p150", []string{}},              // 空の区間
+	}
+	for _, tt := range tests {
+		if got := ch.NodesInRange(tt.start, tt.end); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("NodesInRange(%s, %s) = %v, want %v", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
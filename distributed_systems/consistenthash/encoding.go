@@ -0,0 +1,83 @@
+package consistenthash
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// ringJSON はリング構成のJSON表現
+// 仮想ノードの位置はハッシュ関数から決まるため、replicasとノードごとの重みだけを保存する
+type ringJSON struct {
+	Replicas int          `json:"replicas"`
+	Nodes    []nodeWeight `json:"nodes"`
+}
+
+// nodeWeight はノード名と重み（AddWeightedのweight、Addで追加したノードは1）
+type nodeWeight struct {
+	Node   string `json:"node"`
+	Weight int    `json:"weight"`
+}
+
+// MarshalJSON はリング構成をJSONに変換（json.Marshaler）
+// 形式: {"replicas": N, "nodes": [{"node": 名前, "weight": 重み}, ...]}（ノード名順）
+// ハッシュ関数は保存されないため、復元先でも同じhasherを使うこと
+func (ch *ConsistentHash) MarshalJSON() ([]byte, error) {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	ring := ringJSON{Replicas: ch.replicas, Nodes: make([]nodeWeight, 0, len(ch.nodeHashes))}
+	for _, node := range ch.nodes() {
+		weight := 1
+		if ch.replicas > 0 {
+			weight = len(ch.nodeHashes[node]) / ch.replicas
+		}
+		ring.Nodes = append(ring.Nodes, nodeWeight{Node: node, Weight: weight})
+	}
+	return json.Marshal(ring)
+}
+
+// UnmarshalJSON はJSONからリングを再構築（json.Unmarshaler）
+// ノード名順に追加し直すため、同じhasherを使えば元のリングと同じGetの結果になる
+// （仮想ノードの位置が衝突していた場合のみ、元の追加順によって線形探査の結果が変わりうる）
+// chに設定済みのhasher（未設定ならSHA-1）を使い、既存のノードは全て置き換える
+// AddWithMetaのメタデータは保存されないため、必要なら復元後に登録し直すこと
+// 不正なJSON、replicasや重みが1未満の場合、重複したノードがある場合はエラーを返し、chは変更しない
+func (ch *ConsistentHash) UnmarshalJSON(data []byte) error {
+	var ring ringJSON
+	if err := json.Unmarshal(data, &ring); err != nil {
+		return fmt.Errorf("decode ring: %w", err)
+	}
+	if ring.Replicas < 1 {
+		return fmt.Errorf("replicas must be positive, got %d", ring.Replicas)
+	}
+	seen := make(map[string]bool, len(ring.Nodes))
+	for _, n := range ring.Nodes {
+		if n.Weight < 1 {
+			return fmt.Errorf("node %q has invalid weight %d", n.Node, n.Weight)
+		}
+		if seen[n.Node] {
+			return fmt.Errorf("node %q appears more than once", n.Node)
+		}
+		seen[n.Node] = true
+	}
+	sort.Slice(ring.Nodes, func(i, j int) bool { return ring.Nodes[i].Node < ring.Nodes[j].Node })
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if ch.hasher == nil {
+		ch.hasher = sha1Hasher
+	}
+	ch.replicas = ring.Replicas
+	ch.keys = nil
+	ch.hashMap = make(map[uint32]string)
+	ch.nodeHashes = make(map[string][]uint32)
+	ch.meta = make(map[string]any)
+	for _, n := range ring.Nodes {
+		ch.addNode(n.Node, ch.replicas*n.Weight)
+	}
+	ch.sortKeys()
+	ch.gen++
+	return nil
+}
@@ -0,0 +1,52 @@
+package consistenthash
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+)
+
+func TestJSONRoundTripPreservesRouting(t *testing.T) {
+	ch := New(20)
+	ch.Add("a", "b", "c")
+	ch.AddWeighted("d", 3)
+
+	data, err := json.Marshal(ch)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	restored := New(1)
+	if err := json.Unmarshal(data, restored); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if restored.Replicas() != 20 {
+		t.Errorf("Replicas() = %d, want 20", restored.Replicas())
+	}
+	for i := 0; i < 5000; i++ {
+		key := "key" + strconv.Itoa(i)
+		if got, want := restored.Get(key), ch.Get(key); got != want {
+			t.Fatalf("Get(%q) = %q after restore, want %q", key, got, want)
+		}
+	}
+}
+
+func TestUnmarshalJSONRejectsInvalidConfig(t *testing.T) {
+	tests := []string{
+		`{"replicas":-2,"nodes":[{"node":"a","weight":1}]}`,
+		`{"replicas":0,"nodes":[]}`,
+		`{"replicas":3,"nodes":[{"node":"a","weight":0}]}`,
+		`{"replicas":3,"nodes":[{"node":"a","weight":1},{"node":"a","weight":2}]}`,
+		`{"replicas":`,
+	}
+	for _, input := range tests {
+		ch := New(3)
+		ch.Add("x")
+		if err := json.Unmarshal([]byte(input), ch); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded, want error", input)
+		}
+		if got := ch.GetNodes(); len(got) != 1 || got[0] != "x" {
+			t.Errorf("Unmarshal(%s) modified the ring: %v", input, got)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package consistenthash
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// JumpHash はLamping & VeachのJump Consistent Hashでkeyを[0, numBuckets)のバケットに割り当てる
+// リングを保持せずにO(log n)で計算でき、バケットへの割り当てはほぼ完全に均等になる
+// バケット数をnからn+1に増やすと、移動するのは約1/(n+1)のキーだけ（全て新しいバケットへ移る）
+// バケットは末尾にしか追加・削除できないため、ノードが0..N-1の番号で管理される場合に使う
+// numBucketsが0以下の場合は-1を返す
+func JumpHash(key uint64, numBuckets int) int32 {
+	b, j := int64(-1), int64(0)
+	for j < int64(numBuckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int32(b)
+}
+
+// JumpRing は文字列のキーをJumpHashでノードに割り当てる軽量な実装
+// ノードは追加順にバケット番号0, 1, ...が振られ、削除できるのは最後に追加したノードだけ
+// 仮想ノードを持たないためメモリはノード数分だけで済む
+type JumpRing struct {
+	mu    sync.RWMutex // 以下のフィールドを保護する
+	nodes []string     // バケット番号順のノード
+}
+
+// NewJumpRing は新しいJumpRingを作成
+func NewJumpRing() *JumpRing {
+	return &JumpRing{}
+}
+
+// Add はノードを末尾のバケットとして追加
+func (jr *JumpRing) Add(nodes ...string) {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+
+	jr.nodes = append(jr.nodes, nodes...)
+}
+
+// RemoveLast は最後に追加したノードを削除して返す（ノードがなければ空文字列とfalse）
+// 途中のノードを削除すると後続の全バケットの割り当てが変わるため、末尾からのみ削除できる
+func (jr *JumpRing) RemoveLast() (string, bool) {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+
+	if len(jr.nodes) == 0 {
+		return "", false
+	}
+	node := jr.nodes[len(jr.nodes)-1]
+	jr.nodes = jr.nodes[:len(jr.nodes)-1]
+	return node, true
+}
+
+// Get はキーに対応するノードを取得（ノードがなければ空文字列）
+// キーはFNV-1a（64ビット）で数値に変換してからJumpHashに渡す
+func (jr *JumpRing) Get(key string) string {
+	jr.mu.RLock()
+	defer jr.mu.RUnlock()
+
+	if len(jr.nodes) == 0 {
+		return ""
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return jr.nodes[JumpHash(h.Sum64(), len(jr.nodes))]
+}
+
+// GetNodes はバケット番号順のノードの一覧を返す
+func (jr *JumpRing) GetNodes() []string {
+	jr.mu.RLock()
+	defer jr.mu.RUnlock()
+
+	return append([]string{}, jr.nodes...)
+}
@@ -0,0 +1,76 @@
+package consistenthash
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// 論文の参照実装を移植した各実装（Guava, go-jumpなど）で共通に使われているテストベクタ
+func TestJumpHashReferenceVectors(t *testing.T) {
+	tests := []struct {
+		key        uint64
+		numBuckets int
+		want       int32
+	}{
+		{1, 1, 0},
+		{42, 57, 43},
+		{0xDEAD10CC, 1, 0},
+		{0xDEAD10CC, 666, 361},
+		{256, 1024, 520},
+		{42, 0, -1},
+		{42, -1, -1},
+	}
+	for _, tt := range tests {
+		if got := JumpHash(tt.key, tt.numBuckets); got != tt.want {
+			t.Errorf("JumpHash(%#x, %d) = %d, want %d", tt.key, tt.numBuckets, got, tt.want)
+		}
+	}
+}
+
+func TestJumpHashMovesKeysOnlyToNewBucket(t *testing.T) {
+	for n := 1; n < 20; n++ {
+		moved := 0
+		for key := uint64(0); key < 10000; key++ {
+			before, after := JumpHash(key, n), JumpHash(key, n+1)
+			if before != after {
+				moved++
+				if after != int32(n) {
+					t.Fatalf("key %d moved from %d to %d when growing to %d buckets", key, before, after, n+1)
+				}
+			}
+		}
+		// 移動するのは約1/(n+1)のキー
+		if want := 10000 / (n + 1); moved < want*80/100 || moved > want*120/100 {
+			t.Errorf("growing %d -> %d buckets moved %d keys, want about %d", n, n+1, moved, want)
+		}
+	}
+}
+
+func TestJumpRing(t *testing.T) {
+	jr := NewJumpRing()
+	if got := jr.Get("key"); got != "" {
+		t.Errorf("Get on an empty JumpRing = %q, want empty string", got)
+	}
+
+	jr.Add("a", "b", "c")
+	before := map[string]string{}
+	for i := 0; i < 1000; i++ {
+		key := "key" + strconv.Itoa(i)
+		before[key] = jr.Get(key)
+	}
+
+	// 末尾に追加したノードを削除すると元の割り当てに戻る
+	jr.Add("d")
+	if node, ok := jr.RemoveLast(); !ok || node != "d" {
+		t.Fatalf("RemoveLast() = %q, %v, want d, true", node, ok)
+	}
+	for key, want := range before {
+		if got := jr.Get(key); got != want {
+			t.Errorf("Get(%q) = %q after add/remove, want %q", key, got, want)
+		}
+	}
+	if got, want := jr.GetNodes(), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetNodes() = %v, want %v", got, want)
+	}
+}
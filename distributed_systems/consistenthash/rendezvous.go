@@ -0,0 +1,86 @@
+package consistenthash
+
+import (
+	"sort"
+	"sync"
+)
+
+// Rendezvous はRendezvous Hashing（HRW: Highest Random Weight）でキーの担当ノードを決める
+// キーと各ノードの組をハッシュし、値が最大のノードを担当とする
+// 仮想ノードのソート済み配列を持たないため、少数のノードでも偏りが小さく、
+// ノードの追加・削除で移動するのはそのノードが担当する（していた）キーだけになる
+// 探索はノード数nに対してO(n)なので、ノードが多い場合はConsistentHashの方が速い
+// APIはConsistentHashと揃えてあり、呼び出し側は実装を差し替えられる
+type Rendezvous struct {
+	mu     sync.RWMutex        // 以下のフィールドを保護する
+	nodes  map[string]struct{} // 登録されている物理ノード
+	hasher func([]byte) uint64 // キーとノード名の組のハッシュ関数
+}
+
+// NewRendezvous は新しいRendezvousを作成
+// ハッシュ関数にはConsistentHashと同じくSHA-1のダイジェストの先頭4バイトを使う
+func NewRendezvous() *Rendezvous {
+	return NewRendezvousWithHasher(sha1Hasher)
+}
+
+// NewRendezvousWithHasher はハッシュ関数を指定してRendezvousを作成
+func NewRendezvousWithHasher(hasher func([]byte) uint64) *Rendezvous {
+	return &Rendezvous{
+		nodes:  make(map[string]struct{}),
+		hasher: hasher,
+	}
+}
+
+// Add はノードを追加（登録済みのノードは無視する）
+func (r *Rendezvous) Add(nodes ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, node := range nodes {
+		r.nodes[node] = struct{}{}
+	}
+}
+
+// Remove はノードを削除
+// 登録されていないノードを指定した場合は何もせずfalseを返す
+func (r *Rendezvous) Remove(node string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.nodes[node]; !ok {
+		return false
+	}
+	delete(r.nodes, node)
+	return true
+}
+
+// Get はキーに対応するノードを取得（ノードがなければ空文字列）
+// hash(key+node)が最大のノードを返し、同じ値の場合は名前の小さいノードを選ぶ
+func (r *Rendezvous) Get(key string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var best string
+	var bestScore uint64
+	found := false
+	for node := range r.nodes {
+		score := r.hasher([]byte(key + node))
+		if !found || score > bestScore || (score == bestScore && node < best) {
+			best, bestScore, found = node, score, true
+		}
+	}
+	return best
+}
+
+// GetNodes は登録されているノードの一覧をソートして返す
+func (r *Rendezvous) GetNodes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nodes := make([]string, 0, len(r.nodes))
+	for node := range r.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
@@ -0,0 +1,100 @@
+package consistenthash
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// nodeNames はnode0からnode(n-1)までのノード名を返す
+func nodeNames(n int) []string {
+	nodes := make([]string, n)
+	for i := range nodes {
+		nodes[i] = "node" + strconv.Itoa(i)
+	}
+	return nodes
+}
+
+func TestRendezvousDistribution(t *testing.T) {
+	nodes := nodeNames(5)
+	r := NewRendezvous()
+	r.Add(nodes...)
+
+	const numKeys = 10000
+	counts := map[string]int{}
+	for i := 0; i < numKeys; i++ {
+		counts[r.Get("key"+strconv.Itoa(i))]++
+	}
+
+	// 仮想ノードなしでも各ノードの負荷は平均の±10%以内
+	mean := numKeys / len(nodes)
+	for _, node := range nodes {
+		if n := counts[node]; n < mean*90/100 || n > mean*110/100 {
+			t.Errorf("node %s got %d keys, want within 10%% of %d", node, n, mean)
+		}
+	}
+}
+
+func TestRendezvousRemoveMovesOnlyItsKeys(t *testing.T) {
+	r := NewRendezvous()
+	r.Add(nodeNames(5)...)
+
+	before := map[string]string{}
+	for i := 0; i < 1000; i++ {
+		key := "key" + strconv.Itoa(i)
+		before[key] = r.Get(key)
+	}
+
+	if !r.Remove("node2") {
+		t.Fatal("Remove(node2) = false, want true")
+	}
+	if r.Remove("node2") {
+		t.Error("second Remove(node2) = true, want false")
+	}
+	for key, owner := range before {
+		got := r.Get(key)
+		if owner != "node2" && got != owner {
+			t.Errorf("key %q moved from %s to %s", key, owner, got)
+		}
+		if got == "node2" {
+			t.Errorf("key %q still routed to removed node", key)
+		}
+	}
+
+	if got, want := r.GetNodes(), []string{"node0", "node1", "node3", "node4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetNodes() = %v, want %v", got, want)
+	}
+	if got := NewRendezvous().Get("key"); got != "" {
+		t.Errorf("Get on an empty Rendezvous = %q, want empty string", got)
+	}
+}
+
+// Rendezvousの探索はノード数に比例し、ConsistentHashの探索は二分探索なのでほぼ一定
+func BenchmarkLookup(b *testing.B) {
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+
+	for _, n := range []int{5, 50} {
+		nodes := nodeNames(n)
+		b.Run("Rendezvous/"+strconv.Itoa(n), func(b *testing.B) {
+			r := NewRendezvous()
+			r.Add(nodes...)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				r.Get(keys[i%len(keys)])
+			}
+		})
+		b.Run("ConsistentHash/"+strconv.Itoa(n), func(b *testing.B) {
+			ch := New(DefaultReplicas)
+			ch.Add(nodes...)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ch.Get(keys[i%len(keys)])
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package consistenthash
+
+// RingOpType はリング構成変更の種類
+type RingOpType int
+
+const (
+	RingOpAdd    RingOpType = iota // ノードの追加
+	RingOpRemove                   // ノードの削除
+)
+
+// RingOp はリングに対する1回の構成変更を表す
+type RingOp struct {
+	Type RingOpType
+	Node string
+}
+
+// StepResult は構成変更1ステップ分のシミュレーション結果
+type StepResult struct {
+	Op       RingOp   // 適用した構成変更
+	Moved    int      // 担当ノードが変わったキー数
+	Fraction float64  // 全キーに対する移動キーの割合
+	Nodes    []string // 変更適用後のノード一覧
+}
+
+// DefaultReplicas はSimulateTopologyChangesが使うノードあたりの仮想ノード数
+const DefaultReplicas = 100
+
+// SimulateTopologyChanges は移行計画によるデータ移動量を見積もる
+// initialのノードで構成したリングにopsを順に適用し、各ステップで
+// keysのうち担当ノードが変わったキー数を報告する
+// 実際のリングには一切触れないオフラインの計画用ツール
+// リングの仮想ノード数はDefaultReplicasで、別の値を使う場合はSimulateTopologyChangesWithReplicasを使う
+func SimulateTopologyChanges(initial []string, ops []RingOp, keys []string) []StepResult {
+	return SimulateTopologyChangesWithReplicas(DefaultReplicas, initial, ops, keys)
+}
+
+// SimulateTopologyChangesWithReplicas はノードあたりreplicas個の仮想ノードを持つリングで
+// SimulateTopologyChangesと同じ見積もりを行う
+func SimulateTopologyChangesWithReplicas(replicas int, initial []string, ops []RingOp, keys []string) []StepResult {
+	ch := New(replicas)
+	ch.Add(initial...)
+
+	// 現在の割り当てを記録
+	owners := make(map[string]string, len(keys))
+	for _, key := range keys {
+		owners[key] = ch.Get(key)
+	}
+
+	results := make([]StepResult, 0, len(ops))
+	for _, op := range ops {
+		switch op.Type {
+		case RingOpAdd:
+			ch.Add(op.Node)
+		case RingOpRemove:
+			ch.Remove(op.Node)
+		}
+
+		// 割り当てが変わったキーを数え、次のステップの基準を更新
+		moved := 0
+		for _, key := range keys {
+			node := ch.Get(key)
+			if node != owners[key] {
+				moved++
+				owners[key] = node
+			}
+		}
+
+		fraction := 0.0
+		if len(keys) > 0 {
+			fraction = float64(moved) / float64(len(keys))
+		}
+
+		results = append(results, StepResult{
+			Op:       op,
+			Moved:    moved,
+			Fraction: fraction,
+			Nodes:    ch.GetNodes(),
+		})
+	}
+
+	return results
+}
+
+// MigrationImpact はnewNodeを追加した場合に担当ノードが変わるキーをsampleKeysから返す
+// リングの複製に追加して比較するため、実際のリングは変更しない
+// 移動するのはnewNodeの仮想ノードの直前の区間にあるキーだけで、移動先は全てnewNodeになる
+func (ch *ConsistentHash) MigrationImpact(newNode string, sampleKeys []string) []string {
+	ch.mu.RLock()
+	before := ch.clone()
+	ch.mu.RUnlock()
+
+	after := before.clone()
+	after.Add(newNode)
+	return changedOwners(before, after, sampleKeys)
+}
+
+// RemovalImpact はnodeを削除した場合に担当ノードが変わるキーをsampleKeysから返す
+// MigrationImpactと同様に実際のリングは変更しない。移動するのはnodeが担当していたキーだけになる
+func (ch *ConsistentHash) RemovalImpact(node string, sampleKeys []string) []string {
+	ch.mu.RLock()
+	before := ch.clone()
+	ch.mu.RUnlock()
+
+	after := before.clone()
+	after.Remove(node)
+	return changedOwners(before, after, sampleKeys)
+}
+
+// changedOwners はbeforeとafterで担当ノードが異なるキーを入力の順序で返す
+func changedOwners(before, after *ConsistentHash, keys []string) []string {
+	changed := []string{}
+	for _, key := range keys {
+		if before.Get(key) != after.Get(key) {
+			changed = append(changed, key)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,105 @@
+package consistenthash
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestSimulateTopologyChangesMatchesManualCount(t *testing.T) {
+	initial := []string{"a", "b", "c"}
+	ops := []RingOp{
+		{Type: RingOpAdd, Node: "d"},
+		{Type: RingOpRemove, Node: "a"},
+		{Type: RingOpRemove, Node: "missing"},
+	}
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+
+	results := SimulateTopologyChanges(initial, ops, keys)
+	if len(results) != len(ops) {
+		t.Fatalf("got %d results, want %d", len(results), len(ops))
+	}
+
+	// 同じ操作を手で適用したリングと比較する
+	ch := New(DefaultReplicas)
+	ch.Add(initial...)
+	for i, op := range ops {
+		before := ch.GetMany(keys)
+		if op.Type == RingOpAdd {
+			ch.Add(op.Node)
+		} else {
+			ch.Remove(op.Node)
+		}
+		moved := 0
+		for _, key := range keys {
+			if ch.Get(key) != before[key] {
+				moved++
+			}
+		}
+
+		if results[i].Moved != moved {
+			t.Errorf("step %d: Moved = %d, want %d", i, results[i].Moved, moved)
+		}
+		if want := float64(moved) / float64(len(keys)); results[i].Fraction != want {
+			t.Errorf("step %d: Fraction = %v, want %v", i, results[i].Fraction, want)
+		}
+		if !reflect.DeepEqual(results[i].Nodes, ch.GetNodes()) {
+			t.Errorf("step %d: Nodes = %v, want %v", i, results[i].Nodes, ch.GetNodes())
+		}
+	}
+	if results[2].Moved != 0 {
+		t.Errorf("removing an unknown node moved %d keys", results[2].Moved)
+	}
+}
+
+func TestMigrationAndRemovalImpact(t *testing.T) {
+	// 時計回りに k50, a#0=100, k150, d#0=200, k250, b#0=300, k350
+	ch := NewWithHasher(1, stubHasher(map[string]uint64{
+		"a#0": 100, "b#0": 300, "d#0": 200,
+		"k50": 50, "k150": 150, "k250": 250, "k350": 350,
+	}))
+	ch.Add("a", "b")
+	keys := []string{"k50", "k150", "k250", "k350"}
+	gen := ch.Generation()
+
+	// dの直前の区間(100, 200]にあるキーだけがdへ移る
+	if got, want := ch.MigrationImpact("d", keys), []string{"k150"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MigrationImpact(d) = %v, want %v", got, want)
+	}
+	// bが担当していた区間(100, 300]のキーだけが移る
+	if got, want := ch.RemovalImpact("b", keys), []string{"k150", "k250"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RemovalImpact(b) = %v, want %v", got, want)
+	}
+
+	// リング自体は変更されない
+	if got, want := ch.GetNodes(), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetNodes() = %v, want %v", got, want)
+	}
+	if ch.Generation() != gen {
+		t.Errorf("Generation changed from %d to %d", gen, ch.Generation())
+	}
+}
+
+func TestMigrationImpactMovesKeysOnlyToNewNode(t *testing.T) {
+	ch := New(50)
+	ch.Add("a", "b", "c")
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+
+	moved := ch.MigrationImpact("d", keys)
+	if len(moved) == 0 {
+		t.Fatal("MigrationImpact(d) reported no keys")
+	}
+	after := New(50)
+	after.Add("a", "b", "c", "d")
+	for _, key := range moved {
+		if got := after.Get(key); got != "d" {
+			t.Errorf("key %q moved to %q, want d", key, got)
+		}
+	}
+}
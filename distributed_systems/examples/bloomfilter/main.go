@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"algorithm-in-go/distributed_systems/bloomfilter"
+)
+
+// 使用例とテスト
+func main() {
+	fmt.Println("=== Bloom Filter Demo ===")
+
+	// 1000アイテム、1%の偽陽性率でBloom Filterを作成
+	bf := bloomfilter.NewBloomFilter(1000, 0.01)
+
+	// テストデータを追加
+	items := []string{
+		"apple", "banana", "cherry", "date", "elderberry",
+		"fig", "grape", "honeydew", "kiwi", "lemon",
+		"mango", "nectarine", "orange", "papaya", "quince",
+	}
+
+	fmt.Printf("Adding %d items to Bloom Filter...\n", len(items))
+	for _, item := range items {
+		bf.Add(item)
+	}
+
+	// 統計情報を表示
+	fmt.Println()
+	bf.PrintStats()
+
+	// 存在テスト
+	fmt.Println("\n=== Existence Tests ===")
+
+	// 確実に存在するアイテムのテスト
+	fmt.Println("Testing items that were added:")
+	for _, item := range items[:5] {
+		exists := bf.Test(item)
+		fmt.Printf("'%s': %v\n", item, exists)
+	}
+
+	// 存在しないアイテムのテスト
+	fmt.Println("\nTesting items that were NOT added:")
+	nonExistentItems := []string{"watermelon", "strawberry", "blueberry", "raspberry", "blackberry"}
+	falsePositives := 0
+
+	for _, item := range nonExistentItems {
+		exists := bf.Test(item)
+		fmt.Printf("'%s': %v", item, exists)
+		if exists {
+			fmt.Print(" (FALSE POSITIVE)")
+			falsePositives++
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("\nFalse positives: %d/%d (%.1f%%)\n",
+		falsePositives, len(nonExistentItems),
+		float64(falsePositives)/float64(len(nonExistentItems))*100)
+
+	// 大量データでのテスト
+	fmt.Println("\n=== Large Scale Test ===")
+	largeBF := bloomfilter.NewBloomFilter(10000, 0.001)
+
+	// 10000個のアイテムを追加
+	for i := 0; i < 10000; i++ {
+		largeBF.Add(fmt.Sprintf("item_%d", i))
+	}
+
+	// 存在しないアイテムをテスト
+	falsePositiveCount := 0
+	testCount := 1000
+
+	for i := 10000; i < 10000+testCount; i++ {
+		if largeBF.Test(fmt.Sprintf("item_%d", i)) {
+			falsePositiveCount++
+		}
+	}
+
+	actualFPRate := float64(falsePositiveCount) / float64(testCount)
+
+	fmt.Printf("Large scale test results:\n")
+	fmt.Printf("Added items: 10,000\n")
+	fmt.Printf("Test items (non-existent): %d\n", testCount)
+	fmt.Printf("False positives: %d\n", falsePositiveCount)
+	fmt.Printf("Actual false positive rate: %.4f%% (target: 0.1%%)\n", actualFPRate*100)
+
+	largeBF.PrintStats()
+
+	// 重複したアイテムを追加すると理論値は実際の状態から離れる
+	fmt.Println("\n=== Duplicate Items Test ===")
+	dupBF := bloomfilter.NewBloomFilter(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		dupBF.Add("apple")
+	}
+	fmt.Printf("theoretical FP rate: %.6g, observed FP rate: %.6g\n",
+		dupBF.EstimateFalsePositiveRate(), dupBF.ObservedFalsePositiveRate())
+
+	// ビット範囲ごとに書き戻してフィルタを再構築するテスト
+	fmt.Println("\n=== Merge Range Test ===")
+	rebuilt := bloomfilter.NewBloomFilter(1000, 0.01)
+	size := bf.StatsStruct().Size
+	half := size / 2
+	if err := rebuilt.MergeRange(bf, 0, half); err != nil {
+		panic(err)
+	}
+	fmt.Printf("first half only: equals original = %v\n", rebuilt.Equals(bf))
+	if err := rebuilt.MergeRange(bf, half, size); err != nil {
+		panic(err)
+	}
+	fmt.Printf("both halves: equals original = %v\n", rebuilt.Equals(bf))
+
+	// 構造体をキーにしたテスト
+	fmt.Println("\n=== Typed Filter Test ===")
+	type userKey struct {
+		Tenant string
+		ID     uint64
+	}
+	users := bloomfilter.NewTypedBloomFilter(100, 0.01, func(k userKey) []byte {
+		key := bloomfilter.AppendKeyField(nil, []byte(k.Tenant))
+		return binary.BigEndian.AppendUint64(key, k.ID)
+	})
+	users.Add(userKey{Tenant: "acme", ID: 42})
+	fmt.Printf("{acme 42}: %v\n", users.Test(userKey{Tenant: "acme", ID: 42}))
+	fmt.Printf("{acme 43}: %v\n", users.Test(userKey{Tenant: "acme", ID: 43}))
+
+	// カウンティングフィルタの削除に追従するアイテム数の推定
+	fmt.Println("\n=== Counting Filter Cardinality Test ===")
+	cbf := bloomfilter.NewCountingBloomFilter(1000, 0.01)
+	for i := 0; i < 500; i++ {
+		cbf.Add(fmt.Sprintf("item_%d", i))
+	}
+	fmt.Printf("after adding 500: estimate=%d\n", cbf.EstimateCardinality())
+	for i := 0; i < 200; i++ {
+		cbf.Remove(fmt.Sprintf("item_%d", i))
+	}
+	fmt.Printf("after removing 200: estimate=%d\n", cbf.EstimateCardinality())
+
+	// d-leftフィルタの削除テスト（バケットあたりの目標負荷6と同じ容量にして、
+	// バケットを1つにするため全アイテムが同じバケットに衝突する）
+	fmt.Println("\n=== D-Left Counting Filter Test ===")
+	dlf, err := bloomfilter.NewDLeftCountingFilter(6, 1)
+	if err != nil {
+		panic(err)
+	}
+	for _, item := range []string{"apple", "banana"} {
+		if err := dlf.Add(item); err != nil {
+			panic(err)
+		}
+	}
+	dlf.Remove("apple")
+	fmt.Printf("after removing 'apple': apple=%v banana=%v\n", dlf.Test("apple"), dlf.Test("banana"))
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	"algorithm-in-go/distributed_systems/consistenthash"
+)
+
+// 使用例
+func main() {
+
+	// 仮想ノード数3でコンシステントハッシュを作成
+	ch, err := consistenthash.NewChecked(3)
+	if err != nil {
+		fmt.Println("リングの作成に失敗:", err)
+		return
+	}
+
+	// ノードを追加
+	ch.Add("server1", "server2", "server3")
+
+	fmt.Println("初期ノード:", ch.GetNodes())
+	fmt.Println("ノードあたりの仮想ノード数:", ch.Replicas())
+
+	// キーの分散をテスト
+	keys := []string{"user1", "user2", "user3", "user4", "user5", "data1", "data2", "data3"}
+
+	fmt.Println("\n各キーの分散:")
+	for _, key := range keys {
+		node := ch.Get(key)
+		fmt.Printf("Key: %s -> Node: %s\n", key, node)
+	}
+
+	if node, pos, ok := ch.GetWithPosition("user1"); ok {
+		fmt.Printf("\nuser1はリング上の位置%dの仮想ノード（%s）に割り当てられた\n", pos, node)
+	}
+	fmt.Println("まとめて取得:", ch.GetMany([]string{"user1", "user2", "data1"}))
+	fmt.Println("user1からuser5までの区間を担当するノード:", ch.NodesInRange("user1", "user5"))
+	fmt.Println("\nuser1のレプリカ配置先（3ノード）:", ch.GetN("user1", 3))
+	fmt.Println("各ノードが担当するハッシュ空間の割合:", ch.ArcSizes())
+	fmt.Println("リング上の仮想ノード:")
+	ch.Walk(func(hash uint32, node string) {
+		fmt.Printf("  %10d -> %s\n", hash, node)
+	})
+
+	// 負荷の上限付きで割り当て（平均の1.25倍まで）
+	loads := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		loads[ch.GetBounded(fmt.Sprintf("key%d", i), loads, 1.25)]++
+	}
+	fmt.Println("負荷の上限付きで100キーを割り当てた結果:", loads)
+
+	// ノードを追加
+	fmt.Println("\nserver4を追加:")
+	ch.Add("server4")
+	fmt.Println("ノード:", ch.GetNodes())
+
+	fmt.Println("\nserver4追加後の分散:")
+	for _, key := range keys {
+		node := ch.Get(key)
+		fmt.Printf("Key: %s -> Node: %s\n", key, node)
+	}
+
+	// ノードを削除
+	fmt.Println("\nserver2を削除:")
+	ch.Remove("server2")
+	if err := ch.RemoveChecked("server2"); err != nil {
+		fmt.Println("再度の削除:", err)
+	}
+	fmt.Println("ノード:", ch.GetNodes())
+
+	fmt.Println("\nserver2削除後の分散:")
+	for _, key := range keys {
+		node := ch.Get(key)
+		fmt.Printf("Key: %s -> Node: %s\n", key, node)
+	}
+
+	// リング構成を保存して再構築
+	saved, err := json.Marshal(ch)
+	if err != nil {
+		fmt.Println("リング構成の保存に失敗:", err)
+		return
+	}
+	restored := consistenthash.New(0)
+	if err := json.Unmarshal(saved, restored); err != nil {
+		fmt.Println("リング構成の復元に失敗:", err)
+		return
+	}
+	fmt.Println("\n保存したリング構成:", string(saved))
+	fmt.Println("復元したリングでのuser1の担当:", restored.Get("user1"))
+
+	// ハッシュ関数を差し替えたリング
+	fnvRing := consistenthash.NewWithHasher(3, func(data []byte) uint64 {
+		h := fnv.New64a()
+		h.Write(data)
+		return h.Sum64()
+	})
+	fnvRing.Add("server1", "server2", "server3")
+	fmt.Println("\nFNV-1aのリングでのuser1の担当:", fnvRing.Get("user1"))
+
+	// メタデータ付きのノード
+	metaRing := consistenthash.New(3)
+	metaRing.AddWithMeta("server1", "10.0.0.1:8080 (tokyo)")
+	metaRing.AddWithMeta("server2", "10.0.1.1:8080 (osaka)")
+	node, meta := metaRing.GetMeta("user1")
+	fmt.Printf("\nuser1の担当: %s, メタデータ: %v\n", node, meta)
+
+	// Jump Consistent Hashでの担当
+	jr := consistenthash.NewJumpRing()
+	jr.Add("server1", "server2", "server3")
+	fmt.Println("\nJump Consistent Hashでのuser1の担当:", jr.Get("user1"))
+
+	// Rendezvous Hashingでの担当
+	rv := consistenthash.NewRendezvous()
+	rv.Add("server1", "server2", "server3")
+	fmt.Println("\nRendezvous Hashingでの分散:")
+	for _, key := range keys {
+		fmt.Printf("Key: %s -> Node: %s\n", key, rv.Get(key))
+	}
+}
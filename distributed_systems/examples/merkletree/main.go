@@ -0,0 +1,205 @@
+package main
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+
+	"algorithm-in-go/distributed_systems/merkletree"
+)
+
+// 使用例
+func main() {
+	// テストデータ
+	data := [][]byte{
+		[]byte("apple"),
+		[]byte("banana"),
+		[]byte("cherry"),
+		[]byte("date"),
+		[]byte("elderberry"),
+	}
+
+	fmt.Println("=== Merkle Tree Demo ===")
+	fmt.Println("データ:", []string{"apple", "banana", "cherry", "date", "elderberry"})
+
+	// Merkle Treeを構築
+	tree := merkletree.NewMerkleTree(data)
+
+	fmt.Println("\n=== Tree Structure ===")
+	tree.PrintTree()
+
+	fmt.Printf("RootHash（ツリーを保持しない計算）と一致: %v\n", fmt.Sprintf("%x", merkletree.RootHash(data)) == tree.GetRootHashString())
+
+	encoded, err := tree.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	var decoded merkletree.MerkleTree
+	if err := decoded.UnmarshalBinary(encoded); err != nil {
+		panic(err)
+	}
+	fmt.Printf("バイナリ形式（%dバイト）から復元したルートが一致: %v\n", len(encoded), decoded.GetRootHashString() == tree.GetRootHashString())
+
+	fmt.Printf("\nLeaf Count: %d, Depth: %d\n", tree.LeafCount(), tree.Depth())
+	fmt.Printf("Leavesから再構築したルートが一致: %v\n", merkletree.NewMerkleTree(tree.Leaves()).GetRootHashString() == tree.GetRootHashString())
+
+	// ルートハッシュを表示
+	fmt.Printf("\n=== Root Hash ===\n%s\n", tree.GetRootHashString())
+
+	// 重複したデータの検索テスト
+	fmt.Println("\n=== Find Test ===")
+	logTree := merkletree.NewMerkleTree([][]byte{[]byte("login"), []byte("logout"), []byte("login")})
+	fmt.Printf("'login'の位置: %v, 'reboot'の位置: %v\n", logTree.Find([]byte("login")), logTree.Find([]byte("reboot")))
+
+	// 位置を指定したMerkle Proofのテスト
+	fmt.Println("\n=== Proof By Index Test ===")
+	indexProof, err := tree.GetProofByIndex(4)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("index 4 ('%s')の検証結果: %v\n", data[4], merkletree.VerifyProof(data[4], indexProof, tree.GetRootHash()))
+	fmt.Printf("位置を指定した検証: index 4 = %v, index 2 = %v\n",
+		merkletree.VerifyProofAt(data[4], 4, tree.LeafCount(), indexProof, tree.GetRootHash()),
+		merkletree.VerifyProofAt(data[4], 2, tree.LeafCount(), indexProof, tree.GetRootHash()))
+
+	// リーフの更新テスト
+	fmt.Println("\n=== Update Leaf Test ===")
+	updated := merkletree.NewMerkleTree(data)
+	if err := updated.UpdateLeaf(2, []byte("coconut")); err != nil {
+		panic(err)
+	}
+	rebuilt := merkletree.NewMerkleTree([][]byte{data[0], data[1], []byte("coconut"), data[3], data[4]})
+	fmt.Printf("更新後のルートが再構築と一致: %v\n", updated.GetRootHashString() == rebuilt.GetRootHashString())
+
+	// 2つのツリーの差分のテスト
+	fmt.Println("\n=== Diff Test ===")
+	changed, err := merkletree.Diff(tree, updated)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("変更されたリーフの位置: %v\n", changed)
+
+	// リーフの追加テスト
+	fmt.Println("\n=== Append Test ===")
+	appended := merkletree.NewMerkleTree(nil)
+	for _, d := range data {
+		if err := appended.Append(d); err != nil {
+			panic(err)
+		}
+	}
+	fmt.Printf("1つずつ追加したルートが一括構築と一致: %v\n", appended.GetRootHashString() == tree.GetRootHashString())
+
+	// 一貫性証明のテスト: 先頭3リーフの時点のツリーが現在のツリーの先頭部分であることを示す
+	fmt.Println("\n=== Consistency Proof Test ===")
+	oldTree := merkletree.NewMerkleTree(data[:3])
+	consistency, err := tree.ConsistencyProof(oldTree.LeafCount())
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("size %d -> %dの一貫性証明（%d個のハッシュ）の検証結果: %v\n",
+		oldTree.LeafCount(), tree.LeafCount(), len(consistency),
+		merkletree.VerifyConsistency(oldTree.GetRootHash(), tree.GetRootHash(), oldTree.LeafCount(), tree.LeafCount(), consistency))
+
+	// 複数リーフの包含証明のテスト
+	fmt.Println("\n=== Multi Proof Test ===")
+	multi, err := tree.GetMultiProof([]int{0, 1, 3})
+	if err != nil {
+		panic(err)
+	}
+	individual := 0
+	for _, index := range multi.Indices {
+		single, _ := tree.GetProofByIndex(index)
+		individual += len(single)
+	}
+	fmt.Printf("ハッシュ数: multiproof %d / 個別のプルーフの合計 %d\n", len(multi.Hashes), individual)
+	fmt.Printf("検証結果: %v\n", merkletree.VerifyMultiProof([][]byte{data[0], data[1], data[3]}, multi, tree.GetRootHash()))
+
+	// Merkle Proofのテスト
+	fmt.Println("\n=== Merkle Proof Test ===")
+	testData := []byte("banana")
+
+	proof, err := tree.GetProof(testData)
+	if err == nil {
+		fmt.Printf("'%s'のMerkle Proof:\n", string(testData))
+		for i, step := range proof {
+			side := "R"
+			if step.Left {
+				side = "L"
+			}
+			fmt.Printf("  %d: [%s] %x\n", i, side, step.Hash)
+		}
+
+		// 証明を検証
+		isValid := merkletree.VerifyProof(testData, proof, tree.GetRootHash())
+		fmt.Printf("\n検証結果: %v\n", isValid)
+	} else {
+		fmt.Printf("'%s'のプルーフが見つかりません: %v\n", string(testData), err)
+	}
+
+	// 16進文字列形式のプルーフのテスト
+	proofHex, err := tree.GetProofHex(testData)
+	if err != nil {
+		panic(err)
+	}
+	hexValid, err := merkletree.VerifyProofHex(hex.EncodeToString(testData), proofHex, tree.GetRootHashString())
+	fmt.Printf("16進文字列での検証結果: %v (err: %v)\n", hexValid, err)
+
+	// 存在しないデータのテスト
+	fmt.Println("\n=== Invalid Data Test ===")
+	invalidData := []byte("grape")
+	if _, err := tree.GetProof(invalidData); err != nil {
+		fmt.Printf("'%s'は存在しません（正常）: %v\n", string(invalidData), err)
+	}
+
+	// データの変更を検出するテスト
+	fmt.Println("\n=== Tamper Detection Test ===")
+	tamperedData := []byte("BANANA") // 大文字に改変
+	isValid, computed := merkletree.VerifyProofVerbose(tamperedData, proof, tree.GetRootHash())
+	fmt.Printf("改変されたデータ'%s'の検証: %v（改変が検出された）\n", string(tamperedData), isValid)
+	fmt.Printf("計算されたルート: %x\n期待したルート:   %x\n", computed[:8], tree.GetRootHash()[:8])
+
+	// 奇数個のノードの扱いのテスト: 最後のノードを複製する方式では同じルートになる組
+	fmt.Println("\n=== Odd Node Test ===")
+	abc := merkletree.NewMerkleTree([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	abcc := merkletree.NewMerkleTree([][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("c")})
+	fmt.Printf("[a b c] と [a b c c] のルートが一致: %v\n", abc.GetRootHashString() == abcc.GetRootHashString())
+
+	// 第二原像攻撃のテスト: 内部ノードの2つの子のハッシュを連結したものをリーフのデータとして提示する
+	fmt.Println("\n=== Second Preimage Test ===")
+	forged := append(append([]byte{}, tree.Root.Left.Left.Hash...), tree.Root.Left.Right.Hash...)
+	forgedProof := []merkletree.ProofStep{{Hash: tree.Root.Right.Hash, Left: false}}
+	fmt.Printf("偽造したリーフの検証: %v（ドメイン分離により拒否された）\n", merkletree.VerifyProof(forged, forgedProof, tree.GetRootHash()))
+
+	// 計算済みのリーフのハッシュから構築したツリーのテスト
+	fmt.Println("\n=== Pre-hashed Leaves Test ===")
+	hashes := make([][]byte, len(data))
+	for i := range hashes {
+		hashes[i] = merkletree.NewLeafNode(data[i]).Hash
+	}
+	prehashed := merkletree.NewMerkleTreeFromHashes(hashes)
+	fmt.Printf("データから構築したルートと一致: %v\n", prehashed.GetRootHashString() == tree.GetRootHashString())
+	hashProof, err := prehashed.GetProofByIndex(1)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("index 1のハッシュの検証結果: %v\n", merkletree.VerifyProofFromHash(hashes[1], hashProof, prehashed.GetRootHash()))
+
+	// 疎なMerkle Treeの包含・非包含のテスト
+	fmt.Println("\n=== Sparse Merkle Tree Test ===")
+	smt := merkletree.NewSparseMerkleTree()
+	smt.Update([]byte("alice"), []byte("100"))
+	smt.Update([]byte("bob"), []byte("250"))
+	fmt.Printf("alice=100の包含: %v\n", merkletree.VerifySparseProof(smt.Root(), []byte("alice"), []byte("100"), smt.Prove([]byte("alice"))))
+	fmt.Printf("carolの非包含: %v\n", merkletree.VerifySparseProof(smt.Root(), []byte("carol"), nil, smt.Prove([]byte("carol"))))
+
+	// ハッシュ関数を差し替えたツリーのテスト
+	fmt.Println("\n=== Custom Hasher Test ===")
+	sha512_256 := func(data []byte) []byte {
+		h := sha512.Sum512_256(data)
+		return h[:]
+	}
+	customTree := merkletree.NewMerkleTreeWithHasher(data, sha512_256)
+	customProof, _ := customTree.GetProof(testData)
+	fmt.Printf("SHA-512/256 Root Hash: %s\n", customTree.GetRootHashString())
+	fmt.Printf("検証結果: %v\n", customTree.Verifier().VerifyProof(testData, customProof, customTree.GetRootHash()))
+}
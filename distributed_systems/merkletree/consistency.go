@@ -0,0 +1,105 @@
+package merkletree
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// largestPowerOfTwoBelow はn未満の最大の2のべき乗を返す（n >= 2）
+func largestPowerOfTwoBelow(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+// subtreeHash はリーフ[start, end)のMerkle Tree Hashを返す
+// 2のべき乗の境界に揃った完全な部分木はlevelsのノードをそのまま使い、
+// それ以外はRFC 6962と同様に最大の2のべき乗で分割して計算する
+func (mt *MerkleTree) subtreeHash(start, end int) []byte {
+	n := end - start
+	if n&(n-1) == 0 {
+		level := bits.TrailingZeros(uint(n))
+		return mt.levels[level][start>>level].Hash
+	}
+
+	k := largestPowerOfTwoBelow(n)
+	return hashChildrenWith(mt.hashFunc(), mt.subtreeHash(start, start+k), mt.subtreeHash(start+k, end))
+}
+
+// ConsistencyProof はリーフ数oldSizeの時点のツリーが現在のツリーの先頭部分であることの証明を返す
+// RFC 6962 2.1.2のPROOF(m, D[n])に従う
+// oldSizeが1未満、または現在のリーフ数を超える場合はエラーを返す
+func (mt *MerkleTree) ConsistencyProof(oldSize int) ([][]byte, error) {
+	if oldSize < 1 || oldSize > mt.LeafCount() {
+		return nil, fmt.Errorf("old size %d out of range [1, %d]", oldSize, mt.LeafCount())
+	}
+	return mt.subproof(oldSize, 0, mt.LeafCount(), true), nil
+}
+
+// subproof はRFC 6962のSUBPROOF(m, D[start:end], b)を計算
+// completeは、リーフ[start, start+m)の部分木が古いツリーの部分木そのものであることを表す
+func (mt *MerkleTree) subproof(m, start, end int, complete bool) [][]byte {
+	if m == end-start {
+		if complete {
+			return [][]byte{}
+		}
+		return [][]byte{mt.subtreeHash(start, end)}
+	}
+
+	k := largestPowerOfTwoBelow(end - start)
+	if m <= k {
+		return append(mt.subproof(m, start, start+k, complete), mt.subtreeHash(start+k, end))
+	}
+	return append(mt.subproof(m-k, start+k, end, false), mt.subtreeHash(start, start+k))
+}
+
+// VerifyConsistency はSHA-256で構築したツリーの一貫性証明を検証
+func VerifyConsistency(oldRoot, newRoot []byte, oldSize, newSize int, proof [][]byte) bool {
+	return NewVerifier(hash).VerifyConsistency(oldRoot, newRoot, oldSize, newSize, proof)
+}
+
+// VerifyConsistency はリーフ数oldSizeのルートoldRootが、リーフ数newSizeのルートnewRootの
+// 先頭部分であることの証明を検証する（RFC 9162 2.1.4.2の手順）
+func (v *Verifier) VerifyConsistency(oldRoot, newRoot []byte, oldSize, newSize int, proof [][]byte) bool {
+	if oldSize < 1 || oldSize > newSize {
+		return false
+	}
+	if oldSize == newSize {
+		return len(proof) == 0 && string(oldRoot) == string(newRoot)
+	}
+
+	// 古いツリーが完全な部分木なら、そのルートが証明の先頭に省略されている
+	if oldSize&(oldSize-1) == 0 {
+		proof = append([][]byte{oldRoot}, proof...)
+	}
+	if len(proof) == 0 {
+		return false
+	}
+
+	fn := oldSize - 1
+	sn := newSize - 1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return false
+		}
+
+		if fn&1 == 1 || fn == sn {
+			fr = hashChildrenWith(v.hasher, c, fr)
+			sr = hashChildrenWith(v.hasher, c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = hashChildrenWith(v.hasher, sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+
+	return string(fr) == string(oldRoot) && string(sr) == string(newRoot) && sn == 0
+}
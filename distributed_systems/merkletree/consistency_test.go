@@ -0,0 +1,57 @@
+package merkletree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestConsistencyProofGenuineAppend(t *testing.T) {
+	data := testData(13)
+	for newSize := 1; newSize <= len(data); newSize++ {
+		mt := NewMerkleTree(data[:newSize])
+		for oldSize := 1; oldSize <= newSize; oldSize++ {
+			oldRoot := NewMerkleTree(data[:oldSize]).GetRootHash()
+			proof, err := mt.ConsistencyProof(oldSize)
+			if err != nil {
+				t.Fatalf("ConsistencyProof(%d) of %d leaves: %v", oldSize, newSize, err)
+			}
+			if !VerifyConsistency(oldRoot, mt.GetRootHash(), oldSize, newSize, proof) {
+				t.Fatalf("consistency proof %d -> %d does not verify", oldSize, newSize)
+			}
+		}
+	}
+}
+
+func TestConsistencyProofTampered(t *testing.T) {
+	data := testData(7)
+	mt := NewMerkleTree(data)
+	oldRoot := NewMerkleTree(data[:3]).GetRootHash()
+	proof, err := mt.ConsistencyProof(3)
+	if err != nil {
+		t.Fatalf("ConsistencyProof: %v", err)
+	}
+
+	// 途中のハッシュを1つ書き換えると検証に失敗する
+	for i := range proof {
+		tampered := make([][]byte, len(proof))
+		copy(tampered, proof)
+		tampered[i] = bytes.Clone(proof[i])
+		tampered[i][0] ^= 0xff
+		if VerifyConsistency(oldRoot, mt.GetRootHash(), 3, 7, tampered) {
+			t.Errorf("proof with tampered hash %d verified", i)
+		}
+	}
+
+	// 古いツリーとして別のデータのルートを示しても通らない
+	forked := testData(3)
+	forked[1] = []byte("forked")
+	if VerifyConsistency(NewMerkleTree(forked).GetRootHash(), mt.GetRootHash(), 3, 7, proof) {
+		t.Error("proof verified for an old root that is not a prefix")
+	}
+
+	for _, oldSize := range []int{0, 8} {
+		if _, err := mt.ConsistencyProof(oldSize); err == nil {
+			t.Errorf("ConsistencyProof(%d) of 7 leaves succeeded, want error", oldSize)
+		}
+	}
+}
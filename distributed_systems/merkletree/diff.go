@@ -0,0 +1,36 @@
+package merkletree
+
+import "fmt"
+
+// Diff は2つのツリーでデータが異なるリーフの位置を昇順で返す
+// ルートから降りながら部分木のハッシュを比較し、一致した部分木は探索しないため、
+// 変更が少なければ比較するノード数は変更数×深さ程度で済む
+// 位置ごとの比較にはツリーの形が同じである必要があるため、リーフ数が異なる場合はエラーを返す
+func Diff(a, b *MerkleTree) ([]int, error) {
+	if a.LeafCount() != b.LeafCount() {
+		return nil, fmt.Errorf("merkle trees have different leaf counts: %d and %d", a.LeafCount(), b.LeafCount())
+	}
+
+	diff := []int{}
+	if a.IsEmpty() {
+		return diff, nil
+	}
+
+	var descend func(level, pos int)
+	descend = func(level, pos int) {
+		if string(a.levels[level][pos].Hash) == string(b.levels[level][pos].Hash) {
+			return // 部分木が一致
+		}
+		if level == 0 {
+			diff = append(diff, pos)
+			return
+		}
+
+		for child := 2 * pos; child <= 2*pos+1 && child < len(a.levels[level-1]); child++ {
+			descend(level-1, child)
+		}
+	}
+	descend(len(a.levels)-1, 0)
+
+	return diff, nil
+}
@@ -0,0 +1,35 @@
+package merkletree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffReportsOnlyChangedLeaves(t *testing.T) {
+	data := testData(1000)
+	changed := testData(1000)
+	changed[417] = []byte("changed")
+
+	got, err := Diff(NewMerkleTree(data), NewMerkleTree(changed))
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if want := []int{417}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff = %v, want %v", got, want)
+	}
+
+	// 昇格した右端のリーフを含む複数の変更
+	changed[0] = []byte("first")
+	changed[999] = []byte("last")
+	got, _ = Diff(NewMerkleTree(data), NewMerkleTree(changed))
+	if want := []int{0, 417, 999}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff = %v, want %v", got, want)
+	}
+
+	if got, _ := Diff(NewMerkleTree(data), NewMerkleTree(data)); len(got) != 0 {
+		t.Errorf("Diff of identical trees = %v, want empty", got)
+	}
+	if _, err := Diff(NewMerkleTree(data), NewMerkleTree(data[:999])); err == nil {
+		t.Error("Diff of trees with different leaf counts succeeded, want error")
+	}
+}
@@ -0,0 +1,49 @@
+package merkletree
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ToDOT はツリーをGraphvizのdigraphとしてwに書き出す
+// 各ノードのラベルはハッシュの先頭8文字で、リーフにはデータも付ける
+// データは引用符や制御文字をエスケープするため、任意のバイト列でも安全に出力できる
+// NodeInternerで共有された部分木は1つのノードとして出力する
+func (mt *MerkleTree) ToDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph MerkleTree {\n")
+	b.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+
+	ids := make(map[*Node]string)
+	var visit func(node *Node) string
+	visit = func(node *Node) string {
+		if id, ok := ids[node]; ok {
+			return id
+		}
+
+		id := fmt.Sprintf("n%d", len(ids))
+		ids[node] = id
+
+		label := fmt.Sprintf("%x", node.Hash)[:8]
+		if node.Left == nil && node.Right == nil {
+			label += "\n" + string(node.Data)
+			fmt.Fprintf(&b, "\t%s [label=%s, style=rounded];\n", id, strconv.Quote(label))
+			return id
+		}
+
+		fmt.Fprintf(&b, "\t%s [label=%s];\n", id, strconv.Quote(label))
+		for _, child := range []*Node{node.Left, node.Right} {
+			fmt.Fprintf(&b, "\t%s -> %s;\n", id, visit(child))
+		}
+		return id
+	}
+	if mt.Root != nil {
+		visit(mt.Root)
+	}
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,46 @@
+package merkletree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToDOTNodeAndEdgeCounts(t *testing.T) {
+	// リーフ5つのツリーは内部ノードが4つ、各内部ノードから子への辺が2本
+	data := testData(4)
+	data = append(data, []byte("quote\" and\nnewline"))
+	mt := NewMerkleTree(data)
+
+	var b strings.Builder
+	if err := mt.ToDOT(&b); err != nil {
+		t.Fatalf("ToDOT: %v", err)
+	}
+	out := b.String()
+
+	if !strings.HasPrefix(out, "digraph MerkleTree {\n") || !strings.HasSuffix(out, "}\n") {
+		t.Fatalf("ToDOT output is not a digraph:\n%s", out)
+	}
+	if got := strings.Count(out, "[label="); got != 9 {
+		t.Errorf("ToDOT emitted %d nodes, want 9", got)
+	}
+	if got := strings.Count(out, "style=rounded"); got != 5 {
+		t.Errorf("ToDOT emitted %d leaves, want 5", got)
+	}
+	if got := strings.Count(out, " -> "); got != 8 {
+		t.Errorf("ToDOT emitted %d edges, want 8", got)
+	}
+	// データの引用符と改行はエスケープされ、行を壊さない
+	if !strings.Contains(out, `quote\" and\nnewline`) {
+		t.Errorf("leaf data is not escaped:\n%s", out)
+	}
+}
+
+func TestToDOTEmptyTree(t *testing.T) {
+	var b strings.Builder
+	if err := NewMerkleTree(nil).ToDOT(&b); err != nil {
+		t.Fatalf("ToDOT: %v", err)
+	}
+	if strings.Contains(b.String(), "[label=") {
+		t.Errorf("empty tree emitted nodes:\n%s", b.String())
+	}
+}
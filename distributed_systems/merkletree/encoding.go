@@ -0,0 +1,74 @@
+package merkletree
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// バイナリ形式のバージョン
+const binaryVersion = 1
+
+// MarshalBinary はMerkle Treeをバイナリ形式に変換（encoding.BinaryMarshaler）
+// 内部ノードはリーフから再構築できるため、リーフのデータのみを順に保存する
+// 形式: バージョン(1), リーフ数(8), 各リーフについて データ長(4) + データ（ビッグエンディアン）
+// NewMerkleTreeFromHashesで構築したツリーはデータを持たず再構築できないため、エラーを返す
+func (mt *MerkleTree) MarshalBinary() ([]byte, error) {
+	if mt.prehashed {
+		return nil, errPrehashed
+	}
+	size := 1 + 8
+	for i := 0; i < mt.LeafCount(); i++ {
+		size += 4 + len(mt.levels[0][i].Data)
+	}
+
+	buf := make([]byte, 0, size)
+	buf = append(buf, binaryVersion)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(mt.LeafCount()))
+	for i := 0; i < mt.LeafCount(); i++ {
+		data := mt.levels[0][i].Data
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(data)))
+		buf = append(buf, data...)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary はバイナリ形式からMerkle Treeを再構築（encoding.BinaryUnmarshaler）
+// ハッシュ関数は保存されないため、mtに設定済みのhasher（未設定ならSHA-256）で再構築する
+// NewMerkleTreeWithHasherで作ったツリーは、同じhasherの空のツリーに対して呼ぶこと
+// データが途中で切れている場合や余分なバイトがある場合はエラーを返し、mtは変更しない
+func (mt *MerkleTree) UnmarshalBinary(data []byte) error {
+	if len(data) < 1+8 {
+		return fmt.Errorf("merkle tree data too short: %d bytes", len(data))
+	}
+	if data[0] != binaryVersion {
+		return fmt.Errorf("unsupported merkle tree version %d, expected %d", data[0], binaryVersion)
+	}
+
+	count := binary.BigEndian.Uint64(data[1:9])
+	rest := data[9:]
+
+	// 1リーフあたり最低4バイト必要なので、それを超えるリーフ数は切り詰められている
+	if count > uint64(len(rest)/4) {
+		return fmt.Errorf("merkle tree data truncated: %d leaves do not fit in %d bytes", count, len(rest))
+	}
+
+	leaves := make([][]byte, 0, count)
+	for i := uint64(0); i < count; i++ {
+		if len(rest) < 4 {
+			return fmt.Errorf("merkle tree data truncated at length of leaf %d", i)
+		}
+		n := binary.BigEndian.Uint32(rest)
+		rest = rest[4:]
+		if uint64(len(rest)) < uint64(n) {
+			return fmt.Errorf("merkle tree data truncated in leaf %d: need %d bytes, have %d", i, n, len(rest))
+		}
+		leaves = append(leaves, append([]byte{}, rest[:n]...))
+		rest = rest[n:]
+	}
+	if len(rest) != 0 {
+		return fmt.Errorf("merkle tree data has %d trailing bytes", len(rest))
+	}
+
+	*mt = *NewMerkleTreeWithHasher(leaves, mt.hashFunc())
+	return nil
+}
@@ -0,0 +1,66 @@
+package merkletree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBinaryRoundTripPreservesRootAndProofs(t *testing.T) {
+	for _, n := range []int{0, 1, 6} {
+		data := testData(n)
+		original := NewMerkleTree(data)
+		encoded, err := original.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary: %v", err)
+		}
+
+		var loaded MerkleTree
+		if err := loaded.UnmarshalBinary(encoded); err != nil {
+			t.Fatalf("UnmarshalBinary: %v", err)
+		}
+		if loaded.GetRootHashString() != original.GetRootHashString() {
+			t.Fatalf("n=%d: root after round trip = %s, want %s", n, loaded.GetRootHashString(), original.GetRootHashString())
+		}
+		for i := range data {
+			want, _ := original.GetProofByIndex(i)
+			got, err := loaded.GetProofByIndex(i)
+			if err != nil {
+				t.Fatalf("n=%d: GetProofByIndex(%d) after round trip: %v", n, i, err)
+			}
+			for s := range want {
+				if !bytes.Equal(got[s].Hash, want[s].Hash) || got[s].Left != want[s].Left {
+					t.Fatalf("n=%d: proof for leaf %d differs after round trip", n, i)
+				}
+			}
+		}
+	}
+}
+
+func TestUnmarshalBinaryRejectsMalformedInput(t *testing.T) {
+	encoded, err := NewMerkleTree(testData(3)).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	// 途中で切れたデータはどの位置でもエラーになる
+	for n := range len(encoded) {
+		var mt MerkleTree
+		if err := mt.UnmarshalBinary(encoded[:n]); err == nil {
+			t.Fatalf("UnmarshalBinary accepted %d of %d bytes", n, len(encoded))
+		}
+	}
+
+	badVersion := bytes.Clone(encoded)
+	badVersion[0] = binaryVersion + 1
+	trailing := append(bytes.Clone(encoded), 0)
+	for name, data := range map[string][]byte{"bad version": badVersion, "trailing bytes": trailing} {
+		original := NewMerkleTree(testData(2))
+		root := original.GetRootHashString()
+		if err := original.UnmarshalBinary(data); err == nil {
+			t.Errorf("%s: UnmarshalBinary succeeded, want error", name)
+		}
+		if original.GetRootHashString() != root {
+			t.Errorf("%s: failed UnmarshalBinary modified the tree", name)
+		}
+	}
+}
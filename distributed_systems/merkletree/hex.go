@@ -0,0 +1,87 @@
+package merkletree
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// 16進文字列形式のプルーフの各段の接頭辞（兄弟が左か右か）
+// 兄弟のハッシュだけでは結合の順序が分からないため、各段にProofStep.Leftを接頭辞として付ける
+const (
+	hexLeftPrefix  = "L:"
+	hexRightPrefix = "R:"
+)
+
+// GetProofHex は指定されたデータのMerkle Proofを16進文字列で返す
+// 各段は "L:<兄弟のハッシュ>" または "R:<兄弟のハッシュ>" の形式で、
+// Lは兄弟が左（兄弟||現在のハッシュの順に結合）、Rは兄弟が右であることを表す
+// ハッシュは小文字の16進文字列で、外部のツールとプルーフをそのまま受け渡せる
+func (mt *MerkleTree) GetProofHex(data []byte) ([]string, error) {
+	proof, err := mt.GetProof(data)
+	if err != nil {
+		return nil, err
+	}
+
+	proofHex := make([]string, len(proof))
+	for i, step := range proof {
+		prefix := hexRightPrefix
+		if step.Left {
+			prefix = hexLeftPrefix
+		}
+		proofHex[i] = prefix + hex.EncodeToString(step.Hash)
+	}
+	return proofHex, nil
+}
+
+// parseProofHex はGetProofHexの形式の文字列をProofStepに戻す
+func parseProofHex(proofHex []string) ([]ProofStep, error) {
+	proof := make([]ProofStep, len(proofHex))
+	for i, s := range proofHex {
+		var encoded string
+		switch {
+		case strings.HasPrefix(s, hexLeftPrefix):
+			proof[i].Left = true
+			encoded = s[len(hexLeftPrefix):]
+		case strings.HasPrefix(s, hexRightPrefix):
+			encoded = s[len(hexRightPrefix):]
+		default:
+			return nil, fmt.Errorf("proof step %d %q: missing %q or %q prefix", i, s, hexLeftPrefix, hexRightPrefix)
+		}
+
+		h, err := hex.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("proof step %d %q: %w", i, s, err)
+		}
+		if len(h) == 0 {
+			return nil, fmt.Errorf("proof step %d %q: empty hash", i, s)
+		}
+		proof[i].Hash = h
+	}
+	return proof, nil
+}
+
+// VerifyProofHex はSHA-256で構築したツリーについて、16進文字列のデータ・プルーフ・ルートを検証
+func VerifyProofHex(dataHex string, proofHex []string, rootHex string) (bool, error) {
+	return NewVerifier(hash).VerifyProofHex(dataHex, proofHex, rootHex)
+}
+
+// VerifyProofHex は16進文字列のデータ・プルーフ・ルートをデコードしてVerifyProofで検証
+// proofHexの各段はGetProofHexと同じ "L:<hex>" または "R:<hex>" の形式で受け取る
+// 不正な16進文字列や、接頭辞がない・ハッシュが空の段が含まれる場合は、その段を示すエラーを返す
+func (v *Verifier) VerifyProofHex(dataHex string, proofHex []string, rootHex string) (bool, error) {
+	data, err := hex.DecodeString(dataHex)
+	if err != nil {
+		return false, fmt.Errorf("data: %w", err)
+	}
+	root, err := hex.DecodeString(rootHex)
+	if err != nil {
+		return false, fmt.Errorf("root: %w", err)
+	}
+	proof, err := parseProofHex(proofHex)
+	if err != nil {
+		return false, err
+	}
+
+	return v.VerifyProof(data, proof, root), nil
+}
@@ -0,0 +1,67 @@
+package merkletree
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestProofHexRoundTrip(t *testing.T) {
+	data := testData(5)
+	mt := NewMerkleTree(data)
+	rootHex := mt.GetRootHashString()
+
+	for _, d := range data {
+		proofHex, err := mt.GetProofHex(d)
+		if err != nil {
+			t.Fatalf("GetProofHex(%s): %v", d, err)
+		}
+		for _, step := range proofHex {
+			if !strings.HasPrefix(step, hexLeftPrefix) && !strings.HasPrefix(step, hexRightPrefix) {
+				t.Errorf("proof step %q has no direction prefix", step)
+			}
+		}
+		ok, err := VerifyProofHex(hex.EncodeToString(d), proofHex, rootHex)
+		if err != nil || !ok {
+			t.Errorf("VerifyProofHex(%s) = %v, %v, want true, nil", d, ok, err)
+		}
+	}
+}
+
+func TestVerifyProofHexRejectsMalformedSteps(t *testing.T) {
+	data := testData(4)
+	mt := NewMerkleTree(data)
+	proofHex, err := mt.GetProofHex(data[0])
+	if err != nil {
+		t.Fatalf("GetProofHex: %v", err)
+	}
+	plain := strings.TrimPrefix(proofHex[1], hexRightPrefix)
+	plain = strings.TrimPrefix(plain, hexLeftPrefix)
+
+	tests := []struct {
+		name string
+		step string
+	}{
+		{"no prefix", plain},
+		{"lowercase prefix", "r:" + plain},
+		{"invalid hex", "R:zz"},
+		{"empty hash", "L:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bad := []string{proofHex[0], tt.step}
+			_, err := VerifyProofHex(hex.EncodeToString(data[0]), bad, mt.GetRootHashString())
+			if err == nil {
+				t.Fatalf("VerifyProofHex accepted step %q", tt.step)
+			}
+			// エラーは不正な段の番号と内容を示す
+			if msg := err.Error(); !strings.Contains(msg, "proof step 1") || !strings.Contains(msg, tt.step) {
+				t.Errorf("error %q does not name step 1 %q", msg, tt.step)
+			}
+		})
+	}
+
+	if _, err := VerifyProofHex("xyz", proofHex, mt.GetRootHashString()); err == nil {
+		t.Error("VerifyProofHex accepted malformed data hex")
+	}
+}
@@ -0,0 +1,103 @@
+package merkletree
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// LazyMerkleTree は内部ノードのハッシュを必要になるまで計算しないMerkle Tree
+// リーフのデータのみを保持し、ルートやプルーフが要求された時点で
+// 必要なハッシュを計算してキャッシュする。Nodeは一切割り当てない
+// ルートとプルーフはNewMerkleTreeで構築したツリーと一致する
+type LazyMerkleTree struct {
+	data   [][]byte
+	levels [][][]byte // levels[l][i]: 高さlのi番目のノードのハッシュ（未計算ならnil）
+}
+
+// NewLazyMerkleTree はデータリストから遅延評価のMerkle Treeを作成
+// この時点ではハッシュを一切計算しない
+func NewLazyMerkleTree(data [][]byte) *LazyMerkleTree {
+	lt := &LazyMerkleTree{data: data}
+	if len(data) == 0 {
+		return lt
+	}
+
+	// 各レベルのノード数分のキャッシュ領域だけ確保
+	for n := len(data); ; n = (n + 1) / 2 {
+		lt.levels = append(lt.levels, make([][]byte, n))
+		if n == 1 {
+			break
+		}
+	}
+
+	return lt
+}
+
+// nodeHash は高さlevelのindex番目のノードのハッシュを返す
+// 未計算なら子ノードから再帰的に計算してキャッシュする
+func (lt *LazyMerkleTree) nodeHash(level, index int) []byte {
+	if h := lt.levels[level][index]; h != nil {
+		return h
+	}
+
+	var h []byte
+	if level == 0 {
+		h = leafHash(lt.data[index])
+	} else {
+		left := lt.nodeHash(level-1, 2*index)
+		if 2*index+1 < len(lt.levels[level-1]) {
+			h = hashChildren(left, lt.nodeHash(level-1, 2*index+1))
+		} else {
+			h = left // 奇数個の場合、最後のノードをそのまま昇格
+		}
+	}
+
+	lt.levels[level][index] = h
+	return h
+}
+
+// GetRootHash はルートハッシュを取得
+func (lt *LazyMerkleTree) GetRootHash() []byte {
+	if len(lt.levels) == 0 {
+		return nil
+	}
+	return lt.nodeHash(len(lt.levels)-1, 0)
+}
+
+// GetRootHashString はルートハッシュを16進文字列で取得
+func (lt *LazyMerkleTree) GetRootHashString() string {
+	hash := lt.GetRootHash()
+	if hash == nil {
+		return ""
+	}
+	return fmt.Sprintf("%x", hash)
+}
+
+// GetProof は指定されたデータのMerkle Proofを取得
+// 兄弟ノードのハッシュは部分木全体に依存するため、初回は結局全リーフを
+// ハッシュすることになるが、計算結果はキャッシュされ以降のプルーフで再利用される
+func (lt *LazyMerkleTree) GetProof(data []byte) []ProofStep {
+	// MerkleTree.GetProofと同様に最初に一致したリーフを使う
+	index := -1
+	for i, d := range lt.data {
+		if bytes.Equal(d, data) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil
+	}
+
+	proof := []ProofStep{}
+	for level := 0; level < len(lt.levels)-1; level++ {
+		sibling := index ^ 1
+		if sibling < len(lt.levels[level]) {
+			// 兄弟がいない場合はそのまま昇格しているので、この段は証明に含めない
+			proof = append(proof, ProofStep{Hash: lt.nodeHash(level, sibling), Left: sibling < index})
+		}
+		index /= 2
+	}
+
+	return proof
+}
@@ -0,0 +1,67 @@
+package merkletree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLazyTreeMatchesEagerTree(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 7, 8, 33} {
+		data := testData(n)
+		eager := NewMerkleTree(data)
+		lazy := NewLazyMerkleTree(data)
+
+		// 先にプルーフを要求し、ルートが途中までのキャッシュから正しく計算されることも確認
+		for i, d := range data {
+			want, err := eager.GetProofByIndex(i)
+			if err != nil {
+				t.Fatalf("n=%d: GetProofByIndex(%d): %v", n, i, err)
+			}
+			got := lazy.GetProof(d)
+			if len(got) != len(want) {
+				t.Fatalf("n=%d leaf %d: lazy proof has %d steps, want %d", n, i, len(got), len(want))
+			}
+			for s := range want {
+				if !bytes.Equal(got[s].Hash, want[s].Hash) || got[s].Left != want[s].Left {
+					t.Fatalf("n=%d leaf %d: lazy proof step %d differs from eager", n, i, s)
+				}
+			}
+		}
+		if lazy.GetRootHashString() != eager.GetRootHashString() {
+			t.Errorf("n=%d: lazy root %s, want %s", n, lazy.GetRootHashString(), eager.GetRootHashString())
+		}
+	}
+}
+
+func TestLazyTreeEmptyAndMissing(t *testing.T) {
+	if root := NewLazyMerkleTree(nil).GetRootHash(); root != nil {
+		t.Errorf("empty lazy tree root = %x, want nil", root)
+	}
+	if proof := NewLazyMerkleTree(testData(4)).GetProof([]byte("missing")); proof != nil {
+		t.Errorf("GetProof(missing) = %v, want nil", proof)
+	}
+}
+
+// BenchmarkSingleProofMillionLeaves は100万リーフから1つのプルーフだけを取り出すコストを比較する
+// 遅延評価のツリーはNodeを割り当てないため、構築とプルーフ1回の合計が小さくなる
+func BenchmarkSingleProofMillionLeaves(b *testing.B) {
+	data := testData(1_000_000)
+	target := data[len(data)/2]
+
+	b.Run("eager", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := NewMerkleTree(data).GetProof(target); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("lazy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if NewLazyMerkleTree(data).GetProof(target) == nil {
+				b.Fatal("no proof")
+			}
+		}
+	})
+}
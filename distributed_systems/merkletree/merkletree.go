@@ -0,0 +1,586 @@
+// Package merkletree はMerkle Treeと包含・一貫性の証明、疎なMerkle Treeなどを提供する
+package merkletree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Node はMerkle Treeのノードを表す
+type Node struct {
+	Hash  []byte
+	Left  *Node
+	Right *Node
+	Data  []byte // リーフノードのみ使用
+}
+
+// ProofStep はMerkle Proofの1段分で、兄弟ノードのハッシュとその位置を持つ
+// Leftがtrueなら兄弟は左側にあり、sibling||current の順に結合する
+type ProofStep struct {
+	Hash []byte
+	Left bool
+}
+
+// MerkleTree はMerkle Tree構造を表す
+type MerkleTree struct {
+	Root   *Node
+	levels [][]*Node           // levels[0]が構築時の順序のリーフ、最後のレベルがルート
+	hasher func([]byte) []byte // ノードのハッシュ計算に使う関数
+	// prehashed はNewMerkleTreeFromHashesで構築され、リーフがデータを持たないか
+	prehashed bool
+}
+
+// hashFunc はツリーのハッシュ関数を返す
+// ゼロ値のMerkleTree{}や、Rootを直接設定したツリーではhasherが未設定のためSHA-256を使う
+func (mt *MerkleTree) hashFunc() func([]byte) []byte {
+	if mt.hasher == nil {
+		return hash
+	}
+	return mt.hasher
+}
+
+// hash はデータのSHA256ハッシュを計算
+func hash(data []byte) []byte {
+	h := sha256.Sum256(data)
+	return h[:]
+}
+
+// RFC 6962と同様のドメイン分離のプレフィックス
+// リーフと内部ノードで異なるプレフィックスを付けてハッシュ化することで、
+// 内部ノードの2つの子を連結したものをリーフのデータとして提示する
+// 第二原像攻撃を防ぐ
+const (
+	leafPrefix     byte = 0x00
+	internalPrefix byte = 0x01
+)
+
+// leafHash はリーフのデータをSHA-256でハッシュ化（0x00 || data）
+func leafHash(data []byte) []byte {
+	return leafHashWith(hash, data)
+}
+
+// leafHashWith はリーフのデータをhasherでハッシュ化（0x00 || data）
+func leafHashWith(hasher func([]byte) []byte, data []byte) []byte {
+	prefixed := make([]byte, 0, 1+len(data))
+	prefixed = append(prefixed, leafPrefix)
+	prefixed = append(prefixed, data...)
+	return hasher(prefixed)
+}
+
+// NewLeafNode は新しいリーフノードを作成
+func NewLeafNode(data []byte) *Node {
+	return newLeafNodeWith(hash, data)
+}
+
+// newLeafNodeWith はhasherでハッシュを計算するリーフノードを作成
+func newLeafNodeWith(hasher func([]byte) []byte, data []byte) *Node {
+	return &Node{
+		Hash: leafHashWith(hasher, data),
+		Data: data,
+	}
+}
+
+// hashChildren は左の子と右の子のハッシュを結合してハッシュ化
+func hashChildren(left, right []byte) []byte {
+	return hashChildrenWith(hash, left, right)
+}
+
+// hashChildrenWith は左の子と右の子のハッシュを結合してhasherでハッシュ化（0x01 || left || right）
+func hashChildrenWith(hasher func([]byte) []byte, left, right []byte) []byte {
+	combined := make([]byte, 0, 1+len(left)+len(right))
+	combined = append(combined, internalPrefix)
+	combined = append(combined, left...)
+	combined = append(combined, right...)
+	return hasher(combined)
+}
+
+// NewInternalNode は2つの子ノードから内部ノードを作成
+func NewInternalNode(left, right *Node) *Node {
+	return newInternalNodeWith(hash, left, right)
+}
+
+// newInternalNodeWith はhasherでハッシュを計算する内部ノードを作成
+func newInternalNodeWith(hasher func([]byte) []byte, left, right *Node) *Node {
+	return &Node{
+		Hash:  hashChildrenWith(hasher, left.Hash, right.Hash),
+		Left:  left,
+		Right: right,
+	}
+}
+
+// NewMerkleTree はデータリストからMerkle Treeを構築
+// ハッシュ関数にはSHA-256を使う
+func NewMerkleTree(data [][]byte) *MerkleTree {
+	return NewMerkleTreeWithHasher(data, hash)
+}
+
+// NewMerkleTreeWithHasher はhasherでハッシュを計算するMerkle Treeを構築
+// SHA-512/256など、連携先の仕様が定めるダイジェストに合わせるために使う
+// hasherはツリーに保持され、GetProofとVerifierも同じ関数を使う
+// dataが空の場合は、ルートを持たない空のツリーを返す（IsEmptyがtrueになる）
+func NewMerkleTreeWithHasher(data [][]byte, hasher func([]byte) []byte) *MerkleTree {
+	mt := &MerkleTree{hasher: hasher}
+	if len(data) == 0 {
+		return mt
+	}
+
+	// リーフノードを作成
+	var nodes []*Node
+	for _, d := range data {
+		nodes = append(nodes, newLeafNodeWith(hasher, d))
+	}
+
+	mt.levels = buildLevels(nodes, func(left, right *Node) *Node {
+		return newInternalNodeWith(hasher, left, right)
+	})
+	mt.Root = mt.levels[len(mt.levels)-1][0]
+	return mt
+}
+
+// buildLevels はリーフノードのリストからツリーを下から上へ構築し、各レベルのノードを返す
+// 戻り値の先頭がリーフ、最後がルートだけのレベルになる
+// ノード数が奇数のレベルでは、最後のノードを自身と結合せずそのまま1つ上のレベルに昇格させる
+// （最後のノードを複製する方式では、[a, b, c] と [a, b, c, c] のように
+// 異なるリーフの組が同じルートになってしまう。CVE-2012-2459）
+// この形はRFC 6962のMerkle Tree Hashと一致する
+// newInternalで内部ノードの作り方を差し替えられる
+func buildLevels(nodes []*Node, newInternal func(left, right *Node) *Node) [][]*Node {
+	levels := [][]*Node{nodes}
+	for len(nodes) > 1 {
+		var nextLevel []*Node
+
+		// ペアごとに処理
+		for i := 0; i < len(nodes); i += 2 {
+			if i+1 == len(nodes) {
+				// 奇数個の場合、最後のノードをそのまま昇格
+				nextLevel = append(nextLevel, nodes[i])
+				break
+			}
+
+			parent := newInternal(nodes[i], nodes[i+1])
+			nextLevel = append(nextLevel, parent)
+		}
+
+		nodes = nextLevel
+		levels = append(levels, nodes)
+	}
+
+	return levels
+}
+
+// NodeInterner は同一の部分木を複数のツリー間で共有するための表
+// バージョン違いのデータセットのように、ほとんど同じツリーを多数構築する場合に
+// 変更のない部分木を同じ*Nodeとして再利用しメモリを節約する
+// 共有されたノードは複数のツリーから参照されるため、変更してはならない
+type NodeInterner struct {
+	leaves   map[string]*Node // リーフのハッシュ → リーフノード
+	internal map[string]*Node // 左右の子のハッシュを結合したもの → 内部ノード
+}
+
+// NewNodeInterner は空のNodeInternerを作成
+func NewNodeInterner() *NodeInterner {
+	return &NodeInterner{
+		leaves:   make(map[string]*Node),
+		internal: make(map[string]*Node),
+	}
+}
+
+// leaf は登録済みのリーフがあればそれを返し、なければ作成して登録
+func (in *NodeInterner) leaf(data []byte) *Node {
+	node := NewLeafNode(data)
+	if existing, ok := in.leaves[string(node.Hash)]; ok {
+		return existing
+	}
+	in.leaves[string(node.Hash)] = node
+	return node
+}
+
+// internalNode は同じ子の組み合わせの内部ノードがあればそれを返し、なければ作成して登録
+func (in *NodeInterner) internalNode(left, right *Node) *Node {
+	key := string(left.Hash) + string(right.Hash)
+	if existing, ok := in.internal[key]; ok {
+		return existing
+	}
+	node := NewInternalNode(left, right)
+	in.internal[key] = node
+	return node
+}
+
+// NewMerkleTreeInterned はinternerを通してノードを共有しながらMerkle Treeを構築
+// 同じinternerで構築したツリー同士では、同一の部分木は同じ*Nodeになる
+func NewMerkleTreeInterned(data [][]byte, interner *NodeInterner) *MerkleTree {
+	if len(data) == 0 {
+		return &MerkleTree{hasher: hash}
+	}
+
+	var nodes []*Node
+	for _, d := range data {
+		nodes = append(nodes, interner.leaf(d))
+	}
+
+	levels := buildLevels(nodes, interner.internalNode)
+	return &MerkleTree{Root: levels[len(levels)-1][0], levels: levels, hasher: hash}
+}
+
+// NewCanonicalMerkleTree は集合としてのデータからMerkle Treeを構築
+// itemsをバイト列の辞書順（bytes.Compare順）にソートし重複を取り除いてから構築するため、
+// 同じ集合であれば入力順や重複の有無に関わらず同じルートハッシュになる
+// 引数のスライス自体は変更しない
+func NewCanonicalMerkleTree(items [][]byte) *MerkleTree {
+	sorted := make([][]byte, len(items))
+	copy(sorted, items)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+
+	// ソート済みなので隣接要素の比較で重複を除去できる
+	unique := sorted[:0]
+	for i, item := range sorted {
+		if i > 0 && bytes.Equal(item, sorted[i-1]) {
+			continue
+		}
+		unique = append(unique, item)
+	}
+
+	return NewMerkleTree(unique)
+}
+
+// LeafCount は構築時（とその後のAppend）のリーフ数を返す（空のツリーでは0）
+func (mt *MerkleTree) LeafCount() int {
+	if len(mt.levels) == 0 {
+		return 0
+	}
+	return len(mt.levels[0])
+}
+
+// Leaves はリーフのデータを構築時の順序で返す
+// 内部のデータを書き換えられないよう、各要素はコピーを返す
+func (mt *MerkleTree) Leaves() [][]byte {
+	leaves := make([][]byte, mt.LeafCount())
+	for i := range leaves {
+		leaves[i] = bytes.Clone(mt.levels[0][i].Data)
+	}
+	return leaves
+}
+
+// Find はデータがdataと一致する全てのリーフの位置を昇順で返す（存在しなければ空のスライス）
+// GetProofByIndexと組み合わせると、特定の出現位置についてのプルーフを得られる
+func (mt *MerkleTree) Find(data []byte) []int {
+	indices := []int{}
+	for i := 0; i < mt.LeafCount(); i++ {
+		if bytes.Equal(mt.levels[0][i].Data, data) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// Depth はリーフとルートを含むレベル数を返す（空のツリーでは0）
+// リーフ数nのツリーでは ceil(log2(n)) + 1 になり、プルーフの長さはDepth()-1以下になる
+func (mt *MerkleTree) Depth() int {
+	return len(mt.levels)
+}
+
+// IsEmpty はツリーがリーフを1つも持たないかを返す
+func (mt *MerkleTree) IsEmpty() bool {
+	return mt.Root == nil
+}
+
+// GetRootHash はルートハッシュを取得（空のツリーではnil）
+func (mt *MerkleTree) GetRootHash() []byte {
+	if mt.Root == nil {
+		return nil
+	}
+	return mt.Root.Hash
+}
+
+// GetRootHashString はルートハッシュを16進文字列で取得
+func (mt *MerkleTree) GetRootHashString() string {
+	hash := mt.GetRootHash()
+	if hash == nil {
+		return ""
+	}
+	return fmt.Sprintf("%x", hash)
+}
+
+// StatsJSON はツリーの統計情報をJSONで返す
+// leaf_count: リーフ数, depth: レベル数, root: ルートハッシュの16進文字列
+func (mt *MerkleTree) StatsJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"leaf_count": mt.LeafCount(),
+		"depth":      mt.Depth(),
+		"root":       mt.GetRootHashString(),
+	})
+}
+
+// GetProof は指定されたデータのMerkle Proofを取得
+// ツリーが空の場合や、dataがツリーに含まれない場合はエラーを返す
+// リーフが1つだけのツリーでは、ルートがリーフそのものなので空のプルーフになる
+// プルーフはリーフに近い側から順に並ぶ
+func (mt *MerkleTree) GetProof(data []byte) ([]ProofStep, error) {
+	if mt.IsEmpty() {
+		return nil, errors.New("merkle tree is empty")
+	}
+
+	targetHash := leafHashWith(mt.hashFunc(), data)
+	proof := []ProofStep{}
+
+	// ルートから目標のリーフまでのパスを辿る
+	if mt.getProofHelper(mt.Root, targetHash, &proof) {
+		return proof, nil
+	}
+
+	return nil, errors.New("data not found in merkle tree")
+}
+
+// GetProofByIndex はindex番目のリーフのMerkle Proofを取得
+// 同じデータのリーフが複数ある場合でも、指定した位置のリーフのプルーフになる
+// indexが範囲外の場合はエラーを返す
+func (mt *MerkleTree) GetProofByIndex(index int) ([]ProofStep, error) {
+	if index < 0 || index >= mt.LeafCount() {
+		return nil, fmt.Errorf("leaf index %d out of range [0, %d)", index, mt.LeafCount())
+	}
+
+	proof := []ProofStep{}
+	for level := 0; level < len(mt.levels)-1; level++ {
+		pos := index >> level
+		sibling := pos ^ 1
+		if sibling >= len(mt.levels[level]) {
+			// 兄弟がいない場合はそのまま昇格しているので、この段は証明に含めない
+			continue
+		}
+		proof = append(proof, ProofStep{Hash: mt.levels[level][sibling].Hash, Left: sibling < pos})
+	}
+
+	return proof, nil
+}
+
+// parentOf は高さlevelのpos番目のノードとその兄弟から、1つ上のレベルの親ノードを作り直す
+// 兄弟がいない場合はノード自身がそのまま昇格する
+func (mt *MerkleTree) parentOf(level, pos int) *Node {
+	nodes := mt.levels[level]
+	if pos|1 >= len(nodes) {
+		return nodes[pos]
+	}
+	return newInternalNodeWith(mt.hashFunc(), nodes[pos&^1], nodes[pos|1])
+}
+
+// UpdateLeaf はindex番目のリーフのデータを置き換え、ルートまでの経路だけを再計算する
+// 経路上のノードは書き換えずに新しく作り直すため、NewMerkleTreeInternedで
+// 他のツリーと共有しているノードにも影響しない
+// indexが範囲外の場合や、NewMerkleTreeFromHashesで構築したツリーの場合はエラーを返す
+func (mt *MerkleTree) UpdateLeaf(index int, newData []byte) error {
+	if mt.prehashed {
+		return errPrehashed
+	}
+	if index < 0 || index >= mt.LeafCount() {
+		return fmt.Errorf("leaf index %d out of range [0, %d)", index, mt.LeafCount())
+	}
+
+	mt.levels[0][index] = newLeafNodeWith(mt.hashFunc(), newData)
+	for level := 0; level < len(mt.levels)-1; level++ {
+		mt.levels[level+1][index>>(level+1)] = mt.parentOf(level, index>>level)
+	}
+
+	mt.Root = mt.levels[len(mt.levels)-1][0]
+	return nil
+}
+
+// Append はリーフを末尾に追加し、右端の経路だけを再計算する
+// 各レベルで変わるのは新しいリーフを含むノードだけなので、それより左の部分木はそのまま再利用される
+// 右端で昇格していたノードに新しい兄弟ができた場合は、その2つを結合した親に置き換わる
+// 結果のルートは全てのリーフからNewMerkleTreeで構築したツリーと一致する
+// NewMerkleTreeFromHashesで構築したツリーにはデータを追加できないため、エラーを返す
+func (mt *MerkleTree) Append(data []byte) error {
+	if mt.prehashed {
+		return errPrehashed
+	}
+	if len(mt.levels) == 0 {
+		mt.levels = [][]*Node{nil}
+	}
+
+	index := len(mt.levels[0])
+	mt.levels[0] = append(mt.levels[0], newLeafNodeWith(mt.hashFunc(), data))
+
+	for level := 0; len(mt.levels[level]) > 1; level++ {
+		parent := mt.parentOf(level, index>>level)
+		if level+1 == len(mt.levels) {
+			// ルートより上にレベルが増える
+			mt.levels = append(mt.levels, nil)
+		}
+
+		pos := index >> (level + 1)
+		if pos < len(mt.levels[level+1]) {
+			mt.levels[level+1][pos] = parent
+		} else {
+			mt.levels[level+1] = append(mt.levels[level+1], parent)
+		}
+	}
+
+	mt.Root = mt.levels[len(mt.levels)-1][0]
+	return nil
+}
+
+// getProofHelper はGetProofのヘルパー関数
+func (mt *MerkleTree) getProofHelper(node *Node, targetHash []byte, proof *[]ProofStep) bool {
+	if node == nil {
+		return false
+	}
+
+	// リーフノードの場合
+	if node.Left == nil && node.Right == nil {
+		return string(node.Hash) == string(targetHash)
+	}
+
+	// 左の子ツリーで検索
+	if mt.getProofHelper(node.Left, targetHash, proof) {
+		// 右の子のハッシュを証明に追加
+		*proof = append(*proof, ProofStep{Hash: node.Right.Hash, Left: false})
+		return true
+	}
+
+	// 右の子ツリーで検索
+	if mt.getProofHelper(node.Right, targetHash, proof) {
+		// 左の子のハッシュを証明に追加
+		*proof = append(*proof, ProofStep{Hash: node.Left.Hash, Left: true})
+		return true
+	}
+
+	return false
+}
+
+// Verifier は特定のハッシュ関数でMerkle Proofを検証する
+// ツリーを構築したときと同じハッシュ関数を使う必要がある
+type Verifier struct {
+	hasher func([]byte) []byte
+}
+
+// NewVerifier はhasherで検証するVerifierを作成
+func NewVerifier(hasher func([]byte) []byte) *Verifier {
+	return &Verifier{hasher: hasher}
+}
+
+// Verifier はこのツリーと同じハッシュ関数で検証するVerifierを返す
+func (mt *MerkleTree) Verifier() *Verifier {
+	return NewVerifier(mt.hashFunc())
+}
+
+// VerifyProof はSHA-256で構築したツリーのMerkle Proofを検証
+func VerifyProof(data []byte, proof []ProofStep, rootHash []byte) bool {
+	return NewVerifier(hash).VerifyProof(data, proof, rootHash)
+}
+
+// VerifyProof はMerkle Proofを検証
+// dataにはリーフのプレフィックス、各段の結合には内部ノードのプレフィックスを付けてハッシュ化する
+// 各段で記録された兄弟の位置に従って結合するため、ツリー構築時と同じ left||right の順序になる
+func (v *Verifier) VerifyProof(data []byte, proof []ProofStep, rootHash []byte) bool {
+	ok, _ := v.VerifyProofVerbose(data, proof, rootHash)
+	return ok
+}
+
+// VerifyProofVerbose はSHA-256で構築したツリーのMerkle Proofを検証し、計算したルートも返す
+func VerifyProofVerbose(data []byte, proof []ProofStep, rootHash []byte) (ok bool, computedRoot []byte) {
+	return NewVerifier(hash).VerifyProofVerbose(data, proof, rootHash)
+}
+
+// VerifyProofVerbose はMerkle Proofを検証し、プルーフから計算したルートハッシュも返す
+// 検証に失敗した場合に、期待したルートと計算したルートを並べてログに出すなどのデバッグに使う
+func (v *Verifier) VerifyProofVerbose(data []byte, proof []ProofStep, rootHash []byte) (ok bool, computedRoot []byte) {
+	computedRoot = v.rootFromLeafHash(leafHashWith(v.hasher, data), proof)
+	return string(computedRoot) == string(rootHash), computedRoot
+}
+
+// rootFromLeafHash はリーフのハッシュからプルーフの各段を順に結合してルートハッシュを計算
+func (v *Verifier) rootFromLeafHash(leaf []byte, proof []ProofStep) []byte {
+	currentHash := leaf
+	for _, step := range proof {
+		if step.Left {
+			currentHash = hashChildrenWith(v.hasher, step.Hash, currentHash)
+		} else {
+			currentHash = hashChildrenWith(v.hasher, currentHash, step.Hash)
+		}
+	}
+	return currentHash
+}
+
+// VerifyProofAt はSHA-256で構築したツリーについて、dataがindex番目のリーフであることを検証
+// リクエストの VerifyProofAt(data, index, proof, root) にleafCountを加えている（理由はVerifier.VerifyProofAtを参照）
+func VerifyProofAt(data []byte, index, leafCount int, proof []ProofStep, rootHash []byte) bool {
+	return NewVerifier(hash).VerifyProofAt(data, index, leafCount, proof, rootHash)
+}
+
+// VerifyProofAt はdataがリーフ数leafCountのツリーのindex番目のリーフであることを検証
+// VerifyProofは兄弟の左右の並びしか見ないため、別の位置のプルーフとしても通ってしまう場合がある
+// ここではindexから各段の左右と、兄弟がなく昇格する段（証明に含まれない）を求め、
+// プルーフの各段の向きと段数が一致することも確認する
+// 昇格する段はプルーフに現れず、どの段が昇格するかはツリーのリーフ数で決まるため、
+// indexとLeftの並びだけでは位置を確定できない。例えばリーフ数5のツリーで4番目のリーフは
+// 下の2段で昇格し、プルーフは左の兄弟1つだけになるため、Leftの並びをindexのビットと1対1で照合すると1番目のリーフと区別できない
+// そのためleafCountを引数に取り、昇格する段を飛ばしてindexの各ビットと照合する
+func (v *Verifier) VerifyProofAt(data []byte, index, leafCount int, proof []ProofStep, rootHash []byte) bool {
+	if index < 0 || index >= leafCount {
+		return false
+	}
+
+	step := 0
+	for size, pos := leafCount, index; size > 1; size, pos = (size+1)/2, pos/2 {
+		if pos^1 >= size {
+			continue // 兄弟がなく昇格する段
+		}
+		if step >= len(proof) || proof[step].Left != (pos&1 == 1) {
+			return false
+		}
+		step++
+	}
+
+	return step == len(proof) && v.VerifyProof(data, proof, rootHash)
+}
+
+// PrintTree はツリー構造を表示（デバッグ用）
+func (mt *MerkleTree) PrintTree() {
+	if mt.Root == nil {
+		fmt.Println("Empty tree")
+		return
+	}
+	mt.printNode(mt.Root, "", true)
+}
+
+func (mt *MerkleTree) printNode(node *Node, prefix string, isLast bool) {
+	if node == nil {
+		return
+	}
+
+	// ノードの情報を表示
+	connector := "├── "
+	if isLast {
+		connector = "└── "
+	}
+
+	hashStr := fmt.Sprintf("%x", node.Hash)[:8] // 最初の8文字のみ表示
+	if node.Data != nil {
+		fmt.Printf("%s%s[LEAF] %s (data: %s)\n", prefix, connector, hashStr, string(node.Data))
+	} else {
+		fmt.Printf("%s%s[NODE] %s\n", prefix, connector, hashStr)
+	}
+
+	// 子ノードを表示
+	if node.Left != nil || node.Right != nil {
+		newPrefix := prefix
+		if isLast {
+			newPrefix += "    "
+		} else {
+			newPrefix += "│   "
+		}
+
+		if node.Right != nil {
+			mt.printNode(node.Right, newPrefix, node.Left == nil)
+		}
+		if node.Left != nil {
+			mt.printNode(node.Left, newPrefix, true)
+		}
+	}
+}
@@ -0,0 +1,439 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// testData は"item0", "item1", ...のn個のリーフを返す
+func testData(n int) [][]byte {
+	data := make([][]byte, n)
+	for i := range data {
+		data[i] = []byte("item" + strconv.Itoa(i))
+	}
+	return data
+}
+
+func TestAppendMatchesBatchConstruction(t *testing.T) {
+	data := testData(17)
+	appended := NewMerkleTree(nil)
+	for i, d := range data {
+		if err := appended.Append(d); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+		want := NewMerkleTree(data[:i+1])
+		if appended.GetRootHashString() != want.GetRootHashString() {
+			t.Fatalf("root after %d appends differs from batch construction", i+1)
+		}
+		if appended.Depth() != want.Depth() {
+			t.Fatalf("depth after %d appends = %d, want %d", i+1, appended.Depth(), want.Depth())
+		}
+	}
+
+	for i, d := range data {
+		proof, err := appended.GetProofByIndex(i)
+		if err != nil {
+			t.Fatalf("GetProofByIndex(%d): %v", i, err)
+		}
+		if !VerifyProof(d, proof, appended.GetRootHash()) {
+			t.Errorf("proof for leaf %d does not verify after appends", i)
+		}
+	}
+}
+
+func TestZeroValueTreeUsesSHA256(t *testing.T) {
+	var mt MerkleTree
+	for _, d := range testData(3) {
+		if err := mt.Append(d); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	if got, want := mt.GetRootHashString(), NewMerkleTree(testData(3)).GetRootHashString(); got != want {
+		t.Errorf("zero-value tree root = %s, want %s", got, want)
+	}
+	if err := mt.UpdateLeaf(1, []byte("changed")); err != nil {
+		t.Errorf("UpdateLeaf: %v", err)
+	}
+}
+
+func TestHandBuiltTreeProof(t *testing.T) {
+	left, right := NewLeafNode([]byte("a")), NewLeafNode([]byte("b"))
+	mt := &MerkleTree{Root: NewInternalNode(left, right)}
+
+	proof, err := mt.GetProof([]byte("b"))
+	if err != nil {
+		t.Fatalf("GetProof: %v", err)
+	}
+	if !VerifyProof([]byte("b"), proof, mt.GetRootHash()) {
+		t.Error("proof from a hand-built tree does not verify")
+	}
+	if !mt.Verifier().VerifyProof([]byte("b"), proof, mt.GetRootHash()) {
+		t.Error("Verifier of a hand-built tree rejects a valid proof")
+	}
+	if err := mt.UpdateLeaf(0, []byte("c")); err == nil {
+		t.Error("UpdateLeaf on a tree without leaf levels succeeded, want error")
+	}
+}
+
+func TestCanonicalTreeIgnoresOrderAndDuplicates(t *testing.T) {
+	first := [][]byte{[]byte("cherry"), []byte("apple"), []byte("banana")}
+	second := [][]byte{[]byte("banana"), []byte("cherry"), []byte("apple"), []byte("banana")}
+
+	a, b := NewCanonicalMerkleTree(first), NewCanonicalMerkleTree(second)
+	if a.GetRootHashString() != b.GetRootHashString() {
+		t.Errorf("roots differ for the same set: %s vs %s", a.GetRootHashString(), b.GetRootHashString())
+	}
+	if b.LeafCount() != 3 {
+		t.Errorf("LeafCount() = %d, want 3 after removing the duplicate", b.LeafCount())
+	}
+
+	// 辞書順にソートしたデータから直接構築したツリーと一致する
+	sorted := [][]byte{[]byte("apple"), []byte("banana"), []byte("cherry")}
+	if got, want := a.GetRootHashString(), NewMerkleTree(sorted).GetRootHashString(); got != want {
+		t.Errorf("canonical root = %s, want sorted-order root %s", got, want)
+	}
+	if string(first[0]) != "cherry" {
+		t.Error("NewCanonicalMerkleTree reordered the caller's slice")
+	}
+}
+
+func TestInternedTreesShareUnchangedSubtrees(t *testing.T) {
+	interner := NewNodeInterner()
+	data := testData(8)
+	changed := testData(8)
+	changed[7] = []byte("changed")
+
+	a := NewMerkleTreeInterned(data, interner)
+	b := NewMerkleTreeInterned(changed, interner)
+
+	if a.Root == b.Root {
+		t.Fatal("trees with different leaves share the root node")
+	}
+	// 変更のないリーフ0〜3と4〜5の部分木は同じ*Nodeになる
+	if a.Root.Left != b.Root.Left {
+		t.Error("unchanged left half is not the same *Node")
+	}
+	if a.Root.Right.Left != b.Root.Right.Left {
+		t.Error("unchanged subtree over leaves 4-5 is not the same *Node")
+	}
+	if a.Root.Right.Right == b.Root.Right.Right {
+		t.Error("subtree containing the changed leaf is shared")
+	}
+
+	// 共有しても通常の構築と同じルートになる
+	if got, want := b.GetRootHashString(), NewMerkleTree(changed).GetRootHashString(); got != want {
+		t.Errorf("interned root = %s, want %s", got, want)
+	}
+}
+
+func TestStatsJSONReportsTreeShape(t *testing.T) {
+	mt := NewMerkleTree(testData(5))
+
+	data, err := mt.StatsJSON()
+	if err != nil {
+		t.Fatalf("StatsJSON: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("StatsJSON output is not a JSON object: %v", err)
+	}
+	want := map[string]any{"leaf_count": 5.0, "depth": float64(mt.Depth()), "root": mt.GetRootHashString()}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("StatsJSON = %v, want %v", got, want)
+	}
+}
+
+func TestEmptyAndSingleLeafTrees(t *testing.T) {
+	for _, data := range [][][]byte{nil, {}} {
+		empty := NewMerkleTree(data)
+		if !empty.IsEmpty() || empty.GetRootHash() != nil || empty.LeafCount() != 0 || empty.Depth() != 0 {
+			t.Errorf("NewMerkleTree(%v) is not a well-defined empty tree", data)
+		}
+		if proof, err := empty.GetProof([]byte("a")); err == nil || proof != nil {
+			t.Errorf("GetProof on an empty tree = (%v, %v), want (nil, error)", proof, err)
+		}
+	}
+
+	single := NewMerkleTree([][]byte{[]byte("a")})
+	if single.IsEmpty() || single.LeafCount() != 1 || single.Depth() != 1 {
+		t.Fatalf("single-leaf tree: IsEmpty=%v LeafCount=%d Depth=%d", single.IsEmpty(), single.LeafCount(), single.Depth())
+	}
+	// リーフが1つだけならルートはリーフのハッシュそのもので、プルーフは空になる
+	if got, want := single.GetRootHash(), leafHash([]byte("a")); !bytes.Equal(got, want) {
+		t.Errorf("single-leaf root = %x, want %x", got, want)
+	}
+	proof, err := single.GetProof([]byte("a"))
+	if err != nil || len(proof) != 0 {
+		t.Fatalf("GetProof on a single-leaf tree = (%v, %v), want an empty proof", proof, err)
+	}
+	if !VerifyProof([]byte("a"), proof, single.GetRootHash()) {
+		t.Error("empty proof for the single leaf does not verify")
+	}
+	if _, err := single.GetProof([]byte("absent")); err == nil {
+		t.Error("GetProof of absent data succeeded, want error")
+	}
+}
+
+// legacyVerifyProof は以前のVerifyProofと同じく、兄弟との結合順序をハッシュ値の辞書順で推測して検証する
+func legacyVerifyProof(data []byte, proof []ProofStep, rootHash []byte) bool {
+	current := leafHash(data)
+	for _, step := range proof {
+		if bytes.Compare(current, step.Hash) < 0 {
+			current = hashChildren(current, step.Hash)
+		} else {
+			current = hashChildren(step.Hash, current)
+		}
+	}
+	return bytes.Equal(current, rootHash)
+}
+
+func TestProofUsesRecordedSiblingOrder(t *testing.T) {
+	// 左のリーフのハッシュが右より辞書順で大きくなる組を探す
+	var data [][]byte
+	for _, pair := range [][2]int{{0, 1}, {1, 0}} {
+		candidate := [][]byte{testData(2)[pair[0]], testData(2)[pair[1]]}
+		if bytes.Compare(leafHash(candidate[0]), leafHash(candidate[1])) > 0 {
+			data = candidate
+			break
+		}
+	}
+	mt := NewMerkleTree(data)
+
+	proof, err := mt.GetProofByIndex(0)
+	if err != nil {
+		t.Fatalf("GetProofByIndex: %v", err)
+	}
+	if legacyVerifyProof(data[0], proof, mt.GetRootHash()) {
+		t.Error("legacy lexicographic verification accepted a tree whose left hash sorts after the right")
+	}
+	if !VerifyProof(data[0], proof, mt.GetRootHash()) {
+		t.Error("VerifyProof rejected a genuine proof")
+	}
+	if proof[0].Left {
+		t.Error("sibling of the left leaf is recorded on the left")
+	}
+}
+
+func TestSecondPreimageAttackIsRejected(t *testing.T) {
+	data := testData(4)
+
+	concat := func(a, b []byte) []byte { return append(bytes.Clone(a), b...) }
+	mt := NewMerkleTree(data)
+	root := mt.GetRootHash()
+	level1 := mt.levels[1]
+
+	// ルートの2つの子を連結したものをリーフとして提示する
+	// プレフィックスがなければ hash(forged) がそのままルートの計算と一致してしまう
+	forged := concat(level1[0].Hash, level1[1].Hash)
+	if !bytes.Equal(hash(append([]byte{internalPrefix}, forged...)), root) {
+		t.Fatal("root is not hash(0x01 || left || right)")
+	}
+	if VerifyProof(forged, nil, root) {
+		t.Error("concatenated children of the root verified as a leaf")
+	}
+
+	// 1段下の内部ノードの子を連結し、残りの経路を正しいプルーフとして添えても通らない
+	forged = concat(mt.levels[0][0].Hash, mt.levels[0][1].Hash)
+	proof := []ProofStep{{Hash: level1[1].Hash, Left: false}}
+	if VerifyProof(forged, proof, root) {
+		t.Error("concatenated children of an internal node verified as a leaf")
+	}
+}
+
+func TestUpdateLeafMatchesRebuild(t *testing.T) {
+	for _, n := range []int{1, 2, 5, 8, 13} {
+		data := testData(n)
+		mt := NewMerkleTree(data)
+		for _, index := range []int{0, n / 2, n - 1} {
+			data[index] = []byte("updated" + strconv.Itoa(index))
+			if err := mt.UpdateLeaf(index, data[index]); err != nil {
+				t.Fatalf("n=%d: UpdateLeaf(%d): %v", n, index, err)
+			}
+			rebuilt := NewMerkleTree(data)
+			if mt.GetRootHashString() != rebuilt.GetRootHashString() {
+				t.Fatalf("n=%d: root after UpdateLeaf(%d) differs from a full rebuild", n, index)
+			}
+			proof, err := mt.GetProofByIndex(index)
+			if err != nil || !VerifyProof(data[index], proof, mt.GetRootHash()) {
+				t.Fatalf("n=%d: proof for updated leaf %d does not verify", n, index)
+			}
+		}
+	}
+
+	mt := NewMerkleTree(testData(4))
+	for _, index := range []int{-1, 4} {
+		if err := mt.UpdateLeaf(index, []byte("x")); err == nil {
+			t.Errorf("UpdateLeaf(%d) on 4 leaves succeeded, want error", index)
+		}
+	}
+}
+
+// legacyDuplicateRoot は以前の NewMerkleTree と同じく、奇数個のレベルで最後のノードを複製してルートを計算する
+func legacyDuplicateRoot(data [][]byte) []byte {
+	var level [][]byte
+	for _, d := range data {
+		level = append(level, leafHash(d))
+	}
+	for len(level) > 1 {
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+		var next [][]byte
+		for i := 0; i < len(level); i += 2 {
+			next = append(next, hashChildren(level[i], level[i+1]))
+		}
+		level = next
+	}
+	return level[0]
+}
+
+func TestOddNodeIsPromotedNotDuplicated(t *testing.T) {
+	abc := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	abcc := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("c")}
+
+	// 複製する方式では異なるリーフの組が同じルートになる（CVE-2012-2459）
+	if !bytes.Equal(legacyDuplicateRoot(abc), legacyDuplicateRoot(abcc)) {
+		t.Fatal("legacy duplication scheme does not collide for [a b c] and [a b c c]")
+	}
+	if bytes.Equal(NewMerkleTree(abc).GetRootHash(), NewMerkleTree(abcc).GetRootHash()) {
+		t.Error("[a b c] and [a b c c] still produce the same root")
+	}
+
+	// 昇格する方式では、3つ目のリーフはそのまま1つ上のレベルに上がる
+	want := hashChildren(hashChildren(leafHash(abc[0]), leafHash(abc[1])), leafHash(abc[2]))
+	if got := NewMerkleTree(abc).GetRootHash(); !bytes.Equal(got, want) {
+		t.Errorf("root of [a b c] = %x, want %x", got, want)
+	}
+}
+
+func TestDepthAndLeafCount(t *testing.T) {
+	// Depth = ceil(log2(n)) + 1
+	tests := []struct{ leaves, depth int }{
+		{0, 0}, {1, 1}, {2, 2}, {5, 4}, {8, 4},
+	}
+	for _, tt := range tests {
+		mt := NewMerkleTree(testData(tt.leaves))
+		if mt.Depth() != tt.depth {
+			t.Errorf("Depth() with %d leaves = %d, want %d", tt.leaves, mt.Depth(), tt.depth)
+		}
+		if mt.LeafCount() != tt.leaves {
+			t.Errorf("LeafCount() = %d, want %d", mt.LeafCount(), tt.leaves)
+		}
+	}
+}
+
+func TestLeavesRoundTrip(t *testing.T) {
+	data := testData(7)
+	mt := NewMerkleTree(data)
+
+	leaves := mt.Leaves()
+	if got, want := NewMerkleTree(leaves).GetRootHashString(), mt.GetRootHashString(); got != want {
+		t.Errorf("root rebuilt from Leaves() = %s, want %s", got, want)
+	}
+	for i := range data {
+		if !bytes.Equal(leaves[i], data[i]) {
+			t.Errorf("Leaves()[%d] = %q, want %q", i, leaves[i], data[i])
+		}
+	}
+
+	// 返したスライスを書き換えてもツリーは変わらない
+	leaves[0][0] = 'X'
+	if !bytes.Equal(mt.Leaves()[0], data[0]) {
+		t.Error("mutating Leaves() output changed the tree")
+	}
+	if n := len(NewMerkleTree(nil).Leaves()); n != 0 {
+		t.Errorf("empty tree has %d leaves", n)
+	}
+}
+
+func TestFindReturnsEveryOccurrence(t *testing.T) {
+	data := [][]byte{[]byte("a"), []byte("dup"), []byte("b"), []byte("dup"), []byte("dup")}
+	mt := NewMerkleTree(data)
+
+	if got, want := mt.Find([]byte("dup")), []int{1, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Find(dup) = %v, want %v", got, want)
+	}
+	if got := mt.Find([]byte("absent")); got == nil || len(got) != 0 {
+		t.Errorf("Find(absent) = %#v, want an empty slice", got)
+	}
+
+	// 各出現位置について、その位置に束縛されたプルーフが検証できる
+	for _, index := range mt.Find([]byte("dup")) {
+		proof, err := mt.GetProofByIndex(index)
+		if err != nil || !VerifyProofAt([]byte("dup"), index, mt.LeafCount(), proof, mt.GetRootHash()) {
+			t.Errorf("proof for occurrence at %d does not verify", index)
+		}
+	}
+}
+
+func TestVerifyProofAtBindsIndex(t *testing.T) {
+	for _, n := range []int{2, 3, 5, 8} {
+		data := testData(n)
+		mt := NewMerkleTree(data)
+		for i := range n {
+			proof, err := mt.GetProofByIndex(i)
+			if err != nil {
+				t.Fatalf("GetProofByIndex(%d): %v", i, err)
+			}
+			if !VerifyProofAt(data[i], i, n, proof, mt.GetRootHash()) {
+				t.Fatalf("n=%d: proof for leaf %d does not verify at its own index", n, i)
+			}
+			// 正しいプルーフでも、申告する位置を入れ替えると通らない
+			for j := range n {
+				if j != i && VerifyProofAt(data[i], j, n, proof, mt.GetRootHash()) {
+					t.Errorf("n=%d: proof for leaf %d verified at index %d", n, i, j)
+				}
+			}
+		}
+	}
+
+	// 同じデータが2か所にある場合、VerifyProofは片方のプルーフで通るが位置は区別される
+	data := [][]byte{[]byte("a"), []byte("b"), []byte("a"), []byte("b")}
+	mt := NewMerkleTree(data)
+	proof, _ := mt.GetProofByIndex(0)
+	if !VerifyProof(data[0], proof, mt.GetRootHash()) {
+		t.Fatal("VerifyProof rejected a genuine proof")
+	}
+	if VerifyProofAt(data[0], 2, len(data), proof, mt.GetRootHash()) {
+		t.Error("proof for index 0 verified at index 2 holding the same data")
+	}
+}
+
+func TestVerifyProofVerboseReportsComputedRoot(t *testing.T) {
+	data := testData(7)
+	mt := NewMerkleTree(data)
+	root := mt.GetRootHash()
+
+	proof, err := mt.GetProofByIndex(3)
+	if err != nil {
+		t.Fatalf("GetProofByIndex: %v", err)
+	}
+	ok, computed := VerifyProofVerbose(data[3], proof, root)
+	if !ok || !bytes.Equal(computed, root) {
+		t.Errorf("valid proof: ok = %v, computed root %x, want true and %x", ok, computed, root)
+	}
+
+	// 改ざんしたデータやプルーフでは、計算したルートがツリーのルートと一致しない
+	tampered := append([]ProofStep{}, proof...)
+	tampered[1].Hash = bytes.Repeat([]byte{0xff}, len(tampered[1].Hash))
+	tests := []struct {
+		name  string
+		data  []byte
+		proof []ProofStep
+	}{
+		{"data", []byte("forged"), proof},
+		{"proof", data[3], tampered},
+	}
+	for _, tt := range tests {
+		ok, computed := VerifyProofVerbose(tt.data, tt.proof, root)
+		if ok || bytes.Equal(computed, root) {
+			t.Errorf("tampered %s: ok = %v, computed root %x equals tree root", tt.name, ok, computed)
+		}
+		if VerifyProof(tt.data, tt.proof, root) {
+			t.Errorf("tampered %s: VerifyProof = true", tt.name)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package merkletree
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// MMR はMerkle Mountain Rangeを表す
+// 追記専用のデータ向けに、完全二分木（山）の列として要素を保持する
+// 追加のたびに木全体を再構築する必要がなく、同じ高さの山を結合するだけで済む
+// ノードは後行順（post-order）の位置で格納される
+type MMR struct {
+	nodes   [][]byte // 各位置のノードのハッシュ
+	heights []int    // 各位置のノードの高さ（リーフは0）
+	peaks   []int    // 現在の山の頂上の位置（左から順）
+}
+
+// MMRProof はMMRの包含証明
+type MMRProof struct {
+	Position  int      // 証明対象のリーフの位置
+	Siblings  [][]byte // リーフから山の頂上までの兄弟ノードのハッシュ
+	PeakIndex int      // リーフが属する山の番号
+	Peaks     [][]byte // 証明作成時点の全ての山の頂上のハッシュ
+}
+
+// NewMMR は空のMMRを作成
+func NewMMR() *MMR {
+	return &MMR{}
+}
+
+// Append はデータをリーフとして追加し、そのリーフの位置を返す
+func (m *MMR) Append(data []byte) (position int) {
+	position = len(m.nodes)
+	m.nodes = append(m.nodes, leafHash(data))
+	m.heights = append(m.heights, 0)
+	m.peaks = append(m.peaks, position)
+
+	// 右端の2つの山が同じ高さなら結合して1つの山にする
+	for len(m.peaks) >= 2 {
+		left := m.peaks[len(m.peaks)-2]
+		right := m.peaks[len(m.peaks)-1]
+		if m.heights[left] != m.heights[right] {
+			break
+		}
+
+		parent := len(m.nodes)
+		m.nodes = append(m.nodes, hashChildren(m.nodes[left], m.nodes[right]))
+		m.heights = append(m.heights, m.heights[right]+1)
+		m.peaks = append(m.peaks[:len(m.peaks)-2], parent)
+	}
+
+	return position
+}
+
+// Size はMMRの全ノード数を返す
+func (m *MMR) Size() int {
+	return len(m.nodes)
+}
+
+// peakHashes は現在の山の頂上のハッシュを左から順に返す
+func (m *MMR) peakHashes() [][]byte {
+	hashes := make([][]byte, len(m.peaks))
+	for i, p := range m.peaks {
+		hashes[i] = m.nodes[p]
+	}
+	return hashes
+}
+
+// bagPeaks は山の頂上のハッシュを右から順に結合して1つのルートにまとめる
+func bagPeaks(peaks [][]byte) []byte {
+	if len(peaks) == 0 {
+		return nil
+	}
+
+	root := peaks[len(peaks)-1]
+	for i := len(peaks) - 2; i >= 0; i-- {
+		root = hashChildren(peaks[i], root)
+	}
+	return root
+}
+
+// Root はMMRのルートハッシュを返す（空の場合はnil）
+func (m *MMR) Root() []byte {
+	return bagPeaks(m.peakHashes())
+}
+
+// Prove は指定された位置のリーフの包含証明を作成
+func (m *MMR) Prove(position int) (*MMRProof, error) {
+	if position < 0 || position >= len(m.nodes) {
+		return nil, errors.New("position out of range")
+	}
+	if m.heights[position] != 0 {
+		return nil, errors.New("position is not a leaf")
+	}
+
+	proof := &MMRProof{Position: position, Peaks: m.peakHashes()}
+
+	// 山の頂上に着くまで兄弟ノードを集める
+	pos := position
+	for {
+		peakIndex := -1
+		for i, p := range m.peaks {
+			if p == pos {
+				peakIndex = i
+				break
+			}
+		}
+		if peakIndex >= 0 {
+			proof.PeakIndex = peakIndex
+			return proof, nil
+		}
+
+		h := m.heights[pos]
+		offset := 1<<(h+1) - 1 // 高さhの部分木のノード数
+		if m.heights[pos+1] > h {
+			// 右の子: 直後が親
+			proof.Siblings = append(proof.Siblings, m.nodes[pos-offset])
+			pos++
+		} else {
+			// 左の子: 右の兄弟の直後が親
+			proof.Siblings = append(proof.Siblings, m.nodes[pos+offset])
+			pos += offset + 1
+		}
+	}
+}
+
+// mmrHeight は後行順の位置posにあるノードの高さを位置だけから計算
+func mmrHeight(pos int) int {
+	p := uint(pos + 1)
+	// 全ビットが1（完全な山の頂上）になるまで左の山を飛び越える
+	for p&(p+1) != 0 {
+		p -= 1<<(bits.Len(p)-1) - 1
+	}
+	return bits.Len(p) - 1
+}
+
+// VerifyMMRProof はMMRの包含証明を検証
+// 兄弟ノードとの結合順序はリーフの位置から計算するため、証明側の申告には依存しない
+func VerifyMMRProof(data []byte, proof *MMRProof, root []byte) bool {
+	if proof == nil || proof.Position < 0 || mmrHeight(proof.Position) != 0 {
+		return false
+	}
+	if proof.PeakIndex < 0 || proof.PeakIndex >= len(proof.Peaks) {
+		return false
+	}
+
+	current := leafHash(data)
+	pos := proof.Position
+	for h, sibling := range proof.Siblings {
+		if mmrHeight(pos+1) > h {
+			current = hashChildren(sibling, current)
+			pos++
+		} else {
+			current = hashChildren(current, sibling)
+			pos += 1 << (h + 1)
+		}
+	}
+
+	// 再計算した山の頂上を差し替えてルートを求める
+	peaks := make([][]byte, len(proof.Peaks))
+	copy(peaks, proof.Peaks)
+	peaks[proof.PeakIndex] = current
+
+	return string(bagPeaks(peaks)) == string(root)
+}
@@ -0,0 +1,58 @@
+package merkletree
+
+import "testing"
+
+func TestMMRProofsVerifyAgainstEvolvingRoot(t *testing.T) {
+	m := NewMMR()
+	data := testData(11)
+	var positions []int
+
+	for i, d := range data {
+		positions = append(positions, m.Append(d))
+		root := m.Root()
+
+		// 追加のたびに、それまでの全てのリーフの証明が最新のルートで検証できる
+		for j, pos := range positions {
+			proof, err := m.Prove(pos)
+			if err != nil {
+				t.Fatalf("after %d appends: Prove(%d): %v", i+1, pos, err)
+			}
+			if !VerifyMMRProof(data[j], proof, root) {
+				t.Fatalf("after %d appends: proof for leaf %d does not verify", i+1, j)
+			}
+			if VerifyMMRProof([]byte("forged"), proof, root) {
+				t.Fatalf("after %d appends: proof for leaf %d verifies forged data", i+1, j)
+			}
+		}
+	}
+
+	for pos := range m.Size() {
+		if got, want := mmrHeight(pos), m.heights[pos]; got != want {
+			t.Errorf("mmrHeight(%d) = %d, want %d", pos, got, want)
+		}
+	}
+}
+
+func TestMMRStaleProofAndInvalidPositions(t *testing.T) {
+	m := NewMMR()
+	first := m.Append([]byte("a"))
+	proof, err := m.Prove(first)
+	if err != nil {
+		t.Fatalf("Prove: %v", err)
+	}
+	m.Append([]byte("b"))
+	if VerifyMMRProof([]byte("a"), proof, m.Root()) {
+		t.Error("proof created before an append verified against the new root")
+	}
+
+	// 位置2は"a"と"b"を結合した内部ノード
+	if _, err := m.Prove(2); err == nil {
+		t.Error("Prove of an internal node succeeded, want error")
+	}
+	if _, err := m.Prove(m.Size()); err == nil {
+		t.Error("Prove out of range succeeded, want error")
+	}
+	if NewMMR().Root() != nil {
+		t.Error("empty MMR root is not nil")
+	}
+}
@@ -0,0 +1,143 @@
+package merkletree
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// MultiProof は複数のリーフをまとめて検証するための包含証明
+// 個別のプルーフでは重複して送られる共通の兄弟ノードを1回だけ含み、
+// 検証対象のリーフ同士から計算できるノードは含まない
+type MultiProof struct {
+	Indices   []int    // 証明対象のリーフの位置（昇順、重複なし）
+	LeafCount int      // 証明作成時のリーフ数
+	Hashes    [][]byte // 検証に必要な兄弟ノードのハッシュ（リーフ側のレベルから、各レベル内は左から順）
+}
+
+// GetMultiProof はindicesの位置のリーフをまとめて検証するMultiProofを作成
+// indicesは任意の順序でよく、重複は取り除かれる
+// 空の場合や範囲外の位置を含む場合はエラーを返す
+func (mt *MerkleTree) GetMultiProof(indices []int) (*MultiProof, error) {
+	if len(indices) == 0 {
+		return nil, errors.New("no leaf indices given")
+	}
+
+	known := make([]int, len(indices))
+	copy(known, indices)
+	sort.Ints(known)
+	known = uniqueSorted(known)
+	for _, index := range known {
+		if index < 0 || index >= mt.LeafCount() {
+			return nil, fmt.Errorf("leaf index %d out of range [0, %d)", index, mt.LeafCount())
+		}
+	}
+
+	proof := &MultiProof{
+		Indices:   append([]int{}, known...),
+		LeafCount: mt.LeafCount(),
+		Hashes:    [][]byte{},
+	}
+
+	for level := 0; level < len(mt.levels)-1; level++ {
+		nodes := mt.levels[level]
+		for i, pos := range known {
+			sibling := pos ^ 1
+			if sibling >= len(nodes) {
+				continue // 昇格しているノードには兄弟がない
+			}
+			// 兄弟も既知なら検証側で計算できる（昇順なので既知の兄弟は隣にある）
+			if (i > 0 && known[i-1] == sibling) || (i+1 < len(known) && known[i+1] == sibling) {
+				continue
+			}
+			proof.Hashes = append(proof.Hashes, nodes[sibling].Hash)
+		}
+		known = parentPositions(known)
+	}
+
+	return proof, nil
+}
+
+// uniqueSorted はソート済みのスライスから重複を取り除く
+func uniqueSorted(positions []int) []int {
+	unique := positions[:0]
+	for i, pos := range positions {
+		if i > 0 && pos == positions[i-1] {
+			continue
+		}
+		unique = append(unique, pos)
+	}
+	return unique
+}
+
+// parentPositions は昇順の位置の列から、1つ上のレベルでの位置の列を返す
+func parentPositions(positions []int) []int {
+	parents := make([]int, 0, len(positions))
+	for _, pos := range positions {
+		parents = append(parents, pos/2)
+	}
+	return uniqueSorted(parents)
+}
+
+// VerifyMultiProof はSHA-256で構築したツリーのMultiProofを検証
+func VerifyMultiProof(data [][]byte, proof *MultiProof, rootHash []byte) bool {
+	return NewVerifier(hash).VerifyMultiProof(data, proof, rootHash)
+}
+
+// VerifyMultiProof はMultiProofを検証
+// data[i]はproof.Indices[i]の位置のリーフのデータであること
+// 全てのリーフと証明のハッシュからルートを再計算し、rootHashと比較する
+func (v *Verifier) VerifyMultiProof(data [][]byte, proof *MultiProof, rootHash []byte) bool {
+	if proof == nil || len(proof.Indices) == 0 || len(data) != len(proof.Indices) {
+		return false
+	}
+	for i, index := range proof.Indices {
+		if index < 0 || index >= proof.LeafCount || (i > 0 && index <= proof.Indices[i-1]) {
+			return false
+		}
+	}
+
+	positions := append([]int{}, proof.Indices...)
+	hashes := make([][]byte, len(data))
+	for i, d := range data {
+		hashes[i] = leafHashWith(v.hasher, d)
+	}
+
+	remaining := proof.Hashes
+	for size := proof.LeafCount; size > 1; size = (size + 1) / 2 {
+		var nextPositions []int
+		var nextHashes [][]byte
+
+		for i := 0; i < len(positions); i++ {
+			pos := positions[i]
+			var parent []byte
+
+			switch {
+			case pos^1 >= size:
+				parent = hashes[i] // 昇格
+			case pos&1 == 0 && i+1 < len(positions) && positions[i+1] == pos+1:
+				// 右の兄弟も既知
+				parent = hashChildrenWith(v.hasher, hashes[i], hashes[i+1])
+				i++
+			default:
+				if len(remaining) == 0 {
+					return false
+				}
+				sibling := remaining[0]
+				remaining = remaining[1:]
+				if pos&1 == 0 {
+					parent = hashChildrenWith(v.hasher, hashes[i], sibling)
+				} else {
+					parent = hashChildrenWith(v.hasher, sibling, hashes[i])
+				}
+			}
+
+			nextPositions = append(nextPositions, pos/2)
+			nextHashes = append(nextHashes, parent)
+		}
+
+		positions, hashes = nextPositions, nextHashes
+	}
+
+	return len(remaining) == 0 && len(hashes) == 1 && string(hashes[0]) == string(rootHash)
+}
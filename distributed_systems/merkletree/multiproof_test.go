@@ -0,0 +1,56 @@
+package merkletree
+
+import "testing"
+
+func TestMultiProofHashCount(t *testing.T) {
+	data := testData(16)
+	mt := NewMerkleTree(data)
+
+	tests := []struct {
+		indices []int
+		want    int // マルチプルーフに含まれるハッシュの数
+	}{
+		// 隣接する4リーフは高さ2の部分木を丸ごと計算できるので、残りの2段分だけ必要
+		{[]int{0, 1, 2, 3}, 2},
+		// 両端のリーフは最上段以外で兄弟を共有しない
+		{[]int{15, 0}, 6},
+		{[]int{5}, 4},
+	}
+	for _, tt := range tests {
+		proof, err := mt.GetMultiProof(tt.indices)
+		if err != nil {
+			t.Fatalf("GetMultiProof(%v): %v", tt.indices, err)
+		}
+
+		individual := 0
+		leaves := make([][]byte, len(proof.Indices))
+		for i, index := range proof.Indices {
+			single, err := mt.GetProofByIndex(index)
+			if err != nil {
+				t.Fatalf("GetProofByIndex(%d): %v", index, err)
+			}
+			individual += len(single)
+			leaves[i] = data[index]
+		}
+
+		if len(proof.Hashes) != tt.want {
+			t.Errorf("GetMultiProof(%v) has %d hashes, want %d", tt.indices, len(proof.Hashes), tt.want)
+		}
+		if len(proof.Indices) > 1 && len(proof.Hashes) >= individual {
+			t.Errorf("GetMultiProof(%v) has %d hashes, individual proofs %d", tt.indices, len(proof.Hashes), individual)
+		}
+		if !VerifyMultiProof(leaves, proof, mt.GetRootHash()) {
+			t.Errorf("multiproof for %v does not verify", tt.indices)
+		}
+		leaves[0] = []byte("forged")
+		if VerifyMultiProof(leaves, proof, mt.GetRootHash()) {
+			t.Errorf("multiproof for %v verified forged data", tt.indices)
+		}
+	}
+
+	for _, indices := range [][]int{nil, {16}, {-1}} {
+		if _, err := mt.GetMultiProof(indices); err == nil {
+			t.Errorf("GetMultiProof(%v) succeeded, want error", indices)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package merkletree
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelMinBatch はワーカーに分けて処理する最小のノード数
+// これより少ないレベルはゴルーチンの起動コストの方が大きいため逐次処理する
+const parallelMinBatch = 1024
+
+// NewMerkleTreeParallel はworkers個のゴルーチンでリーフと下位のレベルを並列に計算してMerkle Treeを構築
+// 各レベルのノードを連続した区間に分けてワーカーに割り当て、ノード数が少なくなった上位のレベルは逐次計算する
+// 構築されるツリーはNewMerkleTreeと完全に同じになる
+// workersが0以下の場合はruntime.NumCPU()を使う
+func NewMerkleTreeParallel(data [][]byte, workers int) *MerkleTree {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	mt := &MerkleTree{hasher: hash}
+	if len(data) == 0 {
+		return mt
+	}
+
+	nodes := make([]*Node, len(data))
+	parallelFor(len(nodes), workers, func(i int) {
+		nodes[i] = newLeafNodeWith(hash, data[i])
+	})
+
+	mt.levels = [][]*Node{nodes}
+	for len(nodes) > 1 {
+		children := nodes
+		nodes = make([]*Node, (len(children)+1)/2)
+		parallelFor(len(nodes), workers, func(i int) {
+			if 2*i+1 == len(children) {
+				nodes[i] = children[2*i] // 奇数個の場合、最後のノードをそのまま昇格
+				return
+			}
+			nodes[i] = newInternalNodeWith(hash, children[2*i], children[2*i+1])
+		})
+		mt.levels = append(mt.levels, nodes)
+	}
+
+	mt.Root = nodes[0]
+	return mt
+}
+
+// parallelFor は[0, n)をworkers個の連続した区間に分け、各区間でfnを並列に呼び出す
+// nがparallelMinBatch未満の場合は呼び出し元のゴルーチンで逐次処理する
+func parallelFor(n, workers int, fn func(i int)) {
+	if n < parallelMinBatch || workers == 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	chunk := (n + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += chunk {
+		end := min(start+chunk, n)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
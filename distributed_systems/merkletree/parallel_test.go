@@ -0,0 +1,50 @@
+package merkletree
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestParallelTreeMatchesSequential(t *testing.T) {
+	// parallelMinBatchを超えるレベルと下回るレベルの両方を含む大きさ
+	for _, n := range []int{0, 1, 3, parallelMinBatch + 1, 50_001} {
+		data := testData(n)
+		want := NewMerkleTree(data)
+		for _, workers := range []int{-1, 0, 1, 3, 8} {
+			got := NewMerkleTreeParallel(data, workers)
+			if !bytes.Equal(got.GetRootHash(), want.GetRootHash()) {
+				t.Fatalf("n=%d workers=%d: root differs from NewMerkleTree", n, workers)
+			}
+			if got.Depth() != want.Depth() || got.LeafCount() != want.LeafCount() {
+				t.Fatalf("n=%d workers=%d: shape differs from NewMerkleTree", n, workers)
+			}
+			for level := range want.levels {
+				for i := range want.levels[level] {
+					if !bytes.Equal(got.levels[level][i].Hash, want.levels[level][i].Hash) {
+						t.Fatalf("n=%d workers=%d: node %d at level %d differs", n, workers, i, level)
+					}
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkParallelTree は100万リーフのツリー構築をワーカー数ごとに比較する
+func BenchmarkParallelTree(b *testing.B) {
+	data := testData(1_000_000)
+
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			NewMerkleTree(data)
+		}
+	})
+	for _, workers := range []int{2, 4, runtime.NumCPU()} {
+		b.Run("workers="+strconv.Itoa(workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				NewMerkleTreeParallel(data, workers)
+			}
+		})
+	}
+}
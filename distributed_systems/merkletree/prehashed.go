@@ -0,0 +1,39 @@
+package merkletree
+
+import "errors"
+
+// errPrehashed はリーフのデータを前提とする操作を、データを持たないツリーに対して行った場合のエラー
+var errPrehashed = errors.New("merkle tree was built from leaf hashes and has no leaf data")
+
+// NewMerkleTreeFromHashes は計算済みのリーフのハッシュからMerkle Treeを構築
+// 上流のシステムがリーフのハッシュだけを渡す場合に、再ハッシュせずそのままリーフのHashとして使う
+// 内部ノードはSHA-256（内部ノードのプレフィックス付き）で計算する
+// リーフはデータを持たないため、プルーフはGetProofByIndexで作成し、VerifyProofFromHashで検証する
+// データを前提とするUpdateLeaf、Append、MarshalBinaryはエラーを返す（GetProofはデータが見つからない）
+// hashesが空の場合は、ルートを持たない空のツリーを返す
+func NewMerkleTreeFromHashes(hashes [][]byte) *MerkleTree {
+	mt := &MerkleTree{hasher: hash, prehashed: true}
+	if len(hashes) == 0 {
+		return mt
+	}
+
+	nodes := make([]*Node, len(hashes))
+	for i, h := range hashes {
+		nodes[i] = &Node{Hash: append([]byte{}, h...)}
+	}
+
+	mt.levels = buildLevels(nodes, NewInternalNode)
+	mt.Root = mt.levels[len(mt.levels)-1][0]
+	return mt
+}
+
+// VerifyProofFromHash はSHA-256で構築したツリーについて、計算済みのリーフのハッシュのMerkle Proofを検証
+func VerifyProofFromHash(leafHash []byte, proof []ProofStep, rootHash []byte) bool {
+	return NewVerifier(hash).VerifyProofFromHash(leafHash, proof, rootHash)
+}
+
+// VerifyProofFromHash はleafHashをリーフのハッシュとしてそのまま使い、Merkle Proofを検証
+// NewMerkleTreeFromHashesで構築したツリーのプルーフの検証に使う
+func (v *Verifier) VerifyProofFromHash(leafHash []byte, proof []ProofStep, rootHash []byte) bool {
+	return string(v.rootFromLeafHash(leafHash, proof)) == string(rootHash)
+}
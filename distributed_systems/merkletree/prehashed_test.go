@@ -0,0 +1,60 @@
+package merkletree
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+// externalLeafHash は上流のシステムと同じ方法（0x00 || data のSHA-256）でリーフのハッシュを計算
+func externalLeafHash(data []byte) []byte {
+	h := sha256.Sum256(append([]byte{0x00}, data...))
+	return h[:]
+}
+
+func TestNewMerkleTreeFromHashesMatchesExternalRoot(t *testing.T) {
+	data := testData(5)
+	hashes := make([][]byte, len(data))
+	for i, d := range data {
+		hashes[i] = externalLeafHash(d)
+	}
+
+	mt := NewMerkleTreeFromHashes(hashes)
+	if got, want := mt.GetRootHashString(), NewMerkleTree(data).GetRootHashString(); got != want {
+		t.Fatalf("root = %s, want %s", got, want)
+	}
+
+	// 2リーフのルートを外部で計算した値と比較する
+	pair := NewMerkleTreeFromHashes(hashes[:2])
+	combined := sha256.Sum256(append(append([]byte{0x01}, hashes[0]...), hashes[1]...))
+	if string(pair.GetRootHash()) != string(combined[:]) {
+		t.Errorf("two-leaf root = %x, want %x", pair.GetRootHash(), combined)
+	}
+
+	for i, h := range hashes {
+		proof, err := mt.GetProofByIndex(i)
+		if err != nil {
+			t.Fatalf("GetProofByIndex(%d): %v", i, err)
+		}
+		if !VerifyProofFromHash(h, proof, mt.GetRootHash()) {
+			t.Errorf("proof for pre-hashed leaf %d does not verify", i)
+		}
+	}
+}
+
+func TestPrehashedTreeRejectsDataOperations(t *testing.T) {
+	mt := NewMerkleTreeFromHashes([][]byte{externalLeafHash([]byte("a"))})
+	root := mt.GetRootHashString()
+
+	if _, err := mt.MarshalBinary(); err == nil {
+		t.Error("MarshalBinary succeeded on a pre-hashed tree")
+	}
+	if err := mt.Append([]byte("b")); err == nil {
+		t.Error("Append succeeded on a pre-hashed tree")
+	}
+	if err := mt.UpdateLeaf(0, []byte("b")); err == nil {
+		t.Error("UpdateLeaf succeeded on a pre-hashed tree")
+	}
+	if mt.GetRootHashString() != root {
+		t.Error("rejected operations changed the root")
+	}
+}
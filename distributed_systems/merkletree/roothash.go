@@ -0,0 +1,76 @@
+package merkletree
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// rootAccumulator はリーフを1つずつ受け取りながらルートハッシュを計算する
+// 完全な部分木のルートをスタックに積み、同じ高さの部分木が2つ並んだら結合するため、
+// リーフ数nに対してO(log n)個のハッシュしか保持しない
+type rootAccumulator struct {
+	hashes  [][]byte // 部分木のルート（左から順、高さは単調減少）
+	heights []int    // 各部分木の高さ
+}
+
+// add はリーフのデータを追加
+func (acc *rootAccumulator) add(data []byte) {
+	acc.hashes = append(acc.hashes, leafHash(data))
+	acc.heights = append(acc.heights, 0)
+
+	for n := len(acc.hashes); n >= 2 && acc.heights[n-2] == acc.heights[n-1]; n = len(acc.hashes) {
+		merged := hashChildren(acc.hashes[n-2], acc.hashes[n-1])
+		acc.hashes = append(acc.hashes[:n-2], merged)
+		acc.heights = append(acc.heights[:n-2], acc.heights[n-2]+1)
+	}
+}
+
+// root はスタックの部分木を右から順に結合してルートを返す（リーフがなければnil）
+// 奇数個のノードを昇格させるNewMerkleTreeの形と一致する
+func (acc *rootAccumulator) root() []byte {
+	if len(acc.hashes) == 0 {
+		return nil
+	}
+
+	root := acc.hashes[len(acc.hashes)-1]
+	for i := len(acc.hashes) - 2; i >= 0; i-- {
+		root = hashChildren(acc.hashes[i], root)
+	}
+	return root
+}
+
+// RootHash はNodeを割り当てずにdataのルートハッシュを計算
+// 結果はNewMerkleTree(data).GetRootHash()と一致する
+func RootHash(data [][]byte) []byte {
+	var acc rootAccumulator
+	for _, d := range data {
+		acc.add(d)
+	}
+	return acc.root()
+}
+
+// RootHashFromReader はrをchunkSizeバイトずつのリーフに区切ってルートハッシュを計算
+// 最後のリーフはchunkSizeより短くてもよい。入力全体をメモリに読み込まないため、
+// 巨大なファイルのルートをO(log n)のメモリで求められる
+// 入力が空の場合はnilを返す
+func RootHashFromReader(r io.Reader, chunkSize int) ([]byte, error) {
+	if chunkSize <= 0 {
+		return nil, fmt.Errorf("chunk size must be positive, got %d", chunkSize)
+	}
+
+	var acc rootAccumulator
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			acc.add(buf[:n])
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return acc.root(), nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read chunk: %w", err)
+		}
+	}
+}
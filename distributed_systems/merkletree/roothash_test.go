@@ -0,0 +1,57 @@
+package merkletree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRootHashMatchesTree(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 7, 64, 100_003} {
+		data := testData(n)
+		if got, want := RootHash(data), NewMerkleTree(data).GetRootHash(); !bytes.Equal(got, want) {
+			t.Errorf("RootHash of %d leaves = %x, want %x", n, got, want)
+		}
+	}
+}
+
+func TestRootHashFromReaderMatchesChunkedTree(t *testing.T) {
+	input := bytes.Repeat([]byte("0123456789"), 1001) // 最後のチャンクは短い
+	const chunkSize = 64
+
+	var chunks [][]byte
+	for rest := input; len(rest) > 0; rest = rest[min(chunkSize, len(rest)):] {
+		chunks = append(chunks, rest[:min(chunkSize, len(rest))])
+	}
+
+	got, err := RootHashFromReader(bytes.NewReader(input), chunkSize)
+	if err != nil {
+		t.Fatalf("RootHashFromReader: %v", err)
+	}
+	if want := NewMerkleTree(chunks).GetRootHash(); !bytes.Equal(got, want) {
+		t.Errorf("RootHashFromReader = %x, want %x", got, want)
+	}
+	if root, err := RootHashFromReader(bytes.NewReader(nil), chunkSize); err != nil || root != nil {
+		t.Errorf("RootHashFromReader(empty) = (%x, %v), want (nil, nil)", root, err)
+	}
+	if _, err := RootHashFromReader(bytes.NewReader(input), 0); err == nil {
+		t.Error("RootHashFromReader with chunk size 0 succeeded, want error")
+	}
+}
+
+// BenchmarkRootHash はツリー全体を構築する場合とRootHashのアロケーションを比較する
+func BenchmarkRootHash(b *testing.B) {
+	data := testData(100_000)
+
+	b.Run("tree", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewMerkleTree(data).GetRootHash()
+		}
+	})
+	b.Run("accumulator", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			RootHash(data)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package merkletree
+
+// sparseDepth はSparseMerkleTreeの深さ（キーのSHA-256ハッシュのビット数）
+const sparseDepth = 256
+
+// sparseDefaults[h] は高さhの空の部分木のハッシュ
+// 空のリーフはSHA-256(空列)で、リーフのプレフィックス付きハッシュとは衝突しない
+var sparseDefaults = computeSparseDefaults()
+
+// computeSparseDefaults は各高さの空の部分木のハッシュを計算
+func computeSparseDefaults() [][]byte {
+	defaults := make([][]byte, sparseDepth+1)
+	defaults[0] = hash(nil)
+	for h := 0; h < sparseDepth; h++ {
+		defaults[h+1] = hashChildren(defaults[h], defaults[h])
+	}
+	return defaults
+}
+
+// SparseMerkleTree はキーのハッシュをリーフの位置とする深さ256の疎なMerkle Tree
+// 全てのキーの位置にリーフがあるとみなし、値のないリーフは空として扱うため、
+// キーが含まれること（包含）と含まれないこと（非包含）の両方を証明できる
+// 空でない部分木のノードだけを保持し、空の部分木は高さごとに事前計算したハッシュで表す
+type SparseMerkleTree struct {
+	nodes  map[string][]byte // 空でない部分木のハッシュ（sparseNodeKeyで索引）
+	values map[string][]byte // キー → 値
+	root   []byte
+}
+
+// SparseProof はSparseMerkleTreeの包含・非包含の証明
+// Siblings[h]は高さhでの兄弟ノードのハッシュ（リーフ側から順にsparseDepth個）
+type SparseProof struct {
+	Siblings [][]byte
+}
+
+// NewSparseMerkleTree は空のSparseMerkleTreeを作成
+func NewSparseMerkleTree() *SparseMerkleTree {
+	return &SparseMerkleTree{
+		nodes:  make(map[string][]byte),
+		values: make(map[string][]byte),
+		root:   sparseDefaults[sparseDepth],
+	}
+}
+
+// sparsePath はキーのリーフの位置（SHA-256ハッシュ）を返す
+func sparsePath(key []byte) []byte {
+	return hash(key)
+}
+
+// sparseBit は高さhのノードが親の左右どちらの子か（1なら右）を返す
+// ルート側のビットから順に経路を表すため、高さhでは先頭から255-h番目のビットを見る
+func sparseBit(path []byte, h int) byte {
+	i := sparseDepth - 1 - h
+	return (path[i/8] >> (7 - i%8)) & 1
+}
+
+// sparseNodeKey は経路pathを通る高さhのノードの索引を返す
+// 下位hビットは部分木の中の位置なので0にしてから高さと組み合わせる
+func sparseNodeKey(h int, path []byte) string {
+	key := make([]byte, 2, 2+len(path))
+	key[0], key[1] = byte(h>>8), byte(h)
+	key = append(key, path...)
+	for i := 0; i < h; i++ {
+		bit := sparseDepth - 1 - i
+		key[2+bit/8] &^= 1 << (7 - bit%8)
+	}
+	return string(key)
+}
+
+// siblingPath は高さhで経路pathの兄弟側を通る経路を返す
+func siblingPath(path []byte, h int) []byte {
+	sibling := append([]byte{}, path...)
+	i := sparseDepth - 1 - h
+	sibling[i/8] ^= 1 << (7 - i%8)
+	return sibling
+}
+
+// node は経路pathを通る高さhのノードのハッシュを返す（空なら既定のハッシュ）
+func (st *SparseMerkleTree) node(h int, path []byte) []byte {
+	if n, ok := st.nodes[sparseNodeKey(h, path)]; ok {
+		return n
+	}
+	return sparseDefaults[h]
+}
+
+// setNode は高さhのノードのハッシュを保存する（空の部分木なら削除）
+func (st *SparseMerkleTree) setNode(h int, path []byte, value []byte) {
+	key := sparseNodeKey(h, path)
+	if string(value) == string(sparseDefaults[h]) {
+		delete(st.nodes, key)
+		return
+	}
+	st.nodes[key] = value
+}
+
+// sparseLeafHash は値からリーフのハッシュを計算（空の値なら空のリーフ）
+func sparseLeafHash(value []byte) []byte {
+	if len(value) == 0 {
+		return sparseDefaults[0]
+	}
+	return leafHash(value)
+}
+
+// Update はkeyの値をvalueに設定し、リーフからルートまでの経路を再計算する
+// 空のvalueはキーの削除として扱う
+func (st *SparseMerkleTree) Update(key, value []byte) {
+	if len(value) == 0 {
+		delete(st.values, string(key))
+	} else {
+		st.values[string(key)] = append([]byte{}, value...)
+	}
+
+	path := sparsePath(key)
+	current := sparseLeafHash(value)
+	st.setNode(0, path, current)
+	for h := 0; h < sparseDepth; h++ {
+		sibling := st.node(h, siblingPath(path, h))
+		if sparseBit(path, h) == 0 {
+			current = hashChildren(current, sibling)
+		} else {
+			current = hashChildren(sibling, current)
+		}
+		st.setNode(h+1, path, current)
+	}
+
+	st.root = current
+}
+
+// Get はkeyの値を返す
+func (st *SparseMerkleTree) Get(key []byte) ([]byte, bool) {
+	value, ok := st.values[string(key)]
+	return value, ok
+}
+
+// Root はルートハッシュを返す
+func (st *SparseMerkleTree) Root() []byte {
+	return st.root
+}
+
+// Prove はkeyの証明を作成
+// キーが存在すれば包含の証明、存在しなければ非包含の証明になる
+func (st *SparseMerkleTree) Prove(key []byte) *SparseProof {
+	path := sparsePath(key)
+	proof := &SparseProof{Siblings: make([][]byte, sparseDepth)}
+	for h := 0; h < sparseDepth; h++ {
+		proof.Siblings[h] = st.node(h, siblingPath(path, h))
+	}
+	return proof
+}
+
+// VerifySparseProof はkeyの値がvalueであることを検証
+// valueが空の場合は、keyが含まれないこと（非包含）を検証する
+func VerifySparseProof(root, key, value []byte, proof *SparseProof) bool {
+	if proof == nil || len(proof.Siblings) != sparseDepth {
+		return false
+	}
+
+	path := sparsePath(key)
+	current := sparseLeafHash(value)
+	for h, sibling := range proof.Siblings {
+		if sparseBit(path, h) == 0 {
+			current = hashChildren(current, sibling)
+		} else {
+			current = hashChildren(sibling, current)
+		}
+	}
+
+	return string(current) == string(root)
+}
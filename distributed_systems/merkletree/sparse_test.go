@@ -0,0 +1,77 @@
+package merkletree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSparseInclusionAndNonInclusion(t *testing.T) {
+	st := NewSparseMerkleTree()
+	emptyRoot := st.Root()
+	if proof := st.Prove([]byte("alice")); !VerifySparseProof(emptyRoot, []byte("alice"), nil, proof) {
+		t.Fatal("non-inclusion proof in an empty tree does not verify")
+	}
+
+	st.Update([]byte("alice"), []byte("100"))
+	st.Update([]byte("bob"), []byte("200"))
+	root := st.Root()
+
+	proof := st.Prove([]byte("alice"))
+	if !VerifySparseProof(root, []byte("alice"), []byte("100"), proof) {
+		t.Error("inclusion proof for alice does not verify")
+	}
+	if VerifySparseProof(root, []byte("alice"), []byte("999"), proof) {
+		t.Error("inclusion proof verified a wrong value")
+	}
+	if VerifySparseProof(root, []byte("alice"), nil, proof) {
+		t.Error("non-inclusion verified for a present key")
+	}
+
+	absent := st.Prove([]byte("carol"))
+	if !VerifySparseProof(root, []byte("carol"), nil, absent) {
+		t.Error("non-inclusion proof for carol does not verify")
+	}
+	if VerifySparseProof(root, []byte("carol"), []byte("1"), absent) {
+		t.Error("inclusion verified for an absent key")
+	}
+}
+
+func TestSparseUpdateThenReprove(t *testing.T) {
+	st := NewSparseMerkleTree()
+	st.Update([]byte("alice"), []byte("100"))
+	st.Update([]byte("bob"), []byte("200"))
+	oldRoot := st.Root()
+	oldProof := st.Prove([]byte("alice"))
+
+	st.Update([]byte("alice"), []byte("150"))
+	if bytes.Equal(st.Root(), oldRoot) {
+		t.Fatal("root did not change after Update")
+	}
+	if VerifySparseProof(st.Root(), []byte("alice"), []byte("100"), st.Prove([]byte("alice"))) {
+		t.Error("old value still verifies after Update")
+	}
+	if !VerifySparseProof(st.Root(), []byte("alice"), []byte("150"), st.Prove([]byte("alice"))) {
+		t.Error("new value does not verify after Update")
+	}
+	// bobのプルーフも新しいルートで取り直せば通る
+	if !VerifySparseProof(st.Root(), []byte("bob"), []byte("200"), st.Prove([]byte("bob"))) {
+		t.Error("unchanged key does not verify against the new root")
+	}
+	if VerifySparseProof(st.Root(), []byte("alice"), []byte("100"), oldProof) {
+		t.Error("stale proof verified against the new root")
+	}
+
+	// 空の値での更新は削除として扱われ、ルートは削除前の状態に戻る
+	st.Update([]byte("alice"), []byte("100"))
+	if !bytes.Equal(st.Root(), oldRoot) {
+		t.Error("restoring the old value did not restore the old root")
+	}
+	st.Update([]byte("alice"), nil)
+	st.Update([]byte("bob"), nil)
+	if !bytes.Equal(st.Root(), NewSparseMerkleTree().Root()) {
+		t.Error("deleting every key did not restore the empty root")
+	}
+	if _, ok := st.Get([]byte("alice")); ok {
+		t.Error("Get found a deleted key")
+	}
+}